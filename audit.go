@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"zabbix-mcp-go/audit"
+)
+
+// DefaultAuditFile 未指定审计日志文件时，写在日志目录下的文件名
+const DefaultAuditFile = "audit.jsonl"
+
+// initAudit 按配置打开审计日志，之后经过 ZabbixClient.Call 的写操作都会被记录
+func initAudit(cfg AuditConfig, logDir string) error {
+	if cfg.Disable {
+		GetSugar().Warn("审计日志已禁用，通过本服务执行的Zabbix写操作不会被记录")
+		return nil
+	}
+
+	path := cfg.File
+	if path == "" {
+		path = filepath.Join(logDir, DefaultAuditFile)
+	}
+	if err := audit.Open(path); err != nil {
+		return err
+	}
+	GetSugar().Infof("审计日志: %s", path)
+	return nil
+}
@@ -0,0 +1,296 @@
+// Package audit 记录通过本服务对Zabbix执行的写操作。
+// 审计日志为只追加的JSON lines文件，与应用日志分开，不经过日志级别过滤和切分
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"zabbix-mcp-go/redact"
+)
+
+// Entry 一条审计记录，对应一次非只读的Zabbix API调用
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	Session    string                 `json:"session,omitempty"`   // MCP会话ID
+	Client     string                 `json:"client,omitempty"`    // HTTP认证的客户端名称，stdio或未启用认证时为空
+	Instance   string                 `json:"instance"`            // Zabbix实例名称
+	Tool       string                 `json:"tool,omitempty"`      // 发起调用的工具，不是由工具发起时为空
+	Arguments  map[string]interface{} `json:"arguments,omitempty"` // 已屏蔽敏感信息的工具参数
+	Method     string                 `json:"method"`              // Zabbix API方法
+	ObjectIDs  []string               `json:"object_ids,omitempty"`
+	Result     string                 `json:"result"` // success 或 error
+	Error      string                 `json:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+}
+
+// 调用结果
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Caller 发起Zabbix调用的MCP调用方，由工具处理函数的包装层写入ctx
+type Caller struct {
+	Session   string
+	Client    string
+	Tool      string
+	Arguments map[string]interface{}
+}
+
+// callerKey ctx中保存调用方的键
+type callerKey struct{}
+
+// WithCaller 将调用方保存到ctx，之后通过该ctx发起的写操作都会记录调用方
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext 获取ctx中的调用方，没有时返回零值
+func CallerFromContext(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+var (
+	file *os.File
+	path string
+	mu   sync.Mutex
+)
+
+// Open 打开审计日志文件，文件不存在时创建。未调用 Open 时 Record 不做任何事
+func Open(filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("创建审计日志目录失败: %w", err)
+	}
+	// 审计日志包含工具参数，只允许本用户读取
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("打开审计日志失败: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil {
+		file.Close()
+	}
+	file = f
+	path = filePath
+	return nil
+}
+
+// Close 关闭审计日志文件
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Enabled 是否已打开审计日志
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return file != nil
+}
+
+// Record 记录一次Zabbix写操作。params 和 result 为API调用的参数和返回值，用于提取受影响的对象ID。
+// 写入失败只返回错误，不影响已经完成的Zabbix调用
+func Record(ctx context.Context, instance, method string, params, result interface{}, callErr error, duration time.Duration) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+
+	caller := CallerFromContext(ctx)
+	entry := Entry{
+		Time:       time.Now(),
+		Session:    caller.Session,
+		Client:     caller.Client,
+		Instance:   instance,
+		Tool:       caller.Tool,
+		Arguments:  caller.Arguments,
+		Method:     method,
+		Result:     ResultSuccess,
+		DurationMS: duration.Milliseconds(),
+	}
+	if callErr != nil {
+		entry.Result = ResultError
+		entry.Error = redact.String(callErr.Error())
+		entry.ObjectIDs = objectIDs(params)
+	} else if entry.ObjectIDs = objectIDs(result); len(entry.ObjectIDs) == 0 {
+		entry.ObjectIDs = objectIDs(params)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %w", err)
+	}
+	// 一次Write写入整行，O_APPEND保证多进程追加时也不会交错
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// objectIDs 提取对象ID：删除类方法的参数为ID数组，其他方法从 hostid、hostids、templateids_clear
+// 等以 id 或 ids 结尾的字段中提取，写操作的返回值也是这种形式
+func objectIDs(v interface{}) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(value interface{}) {
+		var id string
+		switch x := value.(type) {
+		case string:
+			id = x
+		case float64:
+			id = fmt.Sprintf("%.0f", x)
+		case int:
+			id = fmt.Sprintf("%d", x)
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	addAll := func(value interface{}) {
+		switch x := value.(type) {
+		case []string:
+			for _, id := range x {
+				add(id)
+			}
+		case []interface{}:
+			for _, id := range x {
+				add(id)
+			}
+		default:
+			add(x)
+		}
+	}
+
+	switch x := v.(type) {
+	case []string, []interface{}:
+		addAll(x)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if isIDKey(key) {
+				addAll(x[key])
+			}
+		}
+	}
+	return ids
+}
+
+// isIDKey 判断参数名是否为对象ID字段
+func isIDKey(key string) bool {
+	return strings.HasSuffix(key, "id") || strings.HasSuffix(key, "ids") || strings.HasSuffix(key, "ids_clear")
+}
+
+// Filter 查询条件，零值字段不过滤
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Session  string
+	Client   string
+	Instance string
+	Tool     string
+	Method   string
+	ObjectID string
+	Failed   bool // 只返回失败的调用
+	// Allow 非nil时只返回该函数允许的实例的记录，用于按调用方可访问的实例过滤
+	Allow func(instance string) bool
+	Limit int // 最多返回的记录数，小于等于0时不限制
+}
+
+// Query 按条件查询审计日志，返回最近的记录，按时间从新到旧排列
+func Query(filter Filter) ([]Entry, error) {
+	mu.Lock()
+	filePath := path
+	mu.Unlock()
+	if filePath == "" {
+		return nil, fmt.Errorf("审计日志未启用")
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取审计日志失败: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	// 工具参数可能很长，放宽单行长度限制
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// 进程异常退出时最后一行可能不完整，跳过
+			continue
+		}
+		if !filter.match(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		if filter.Limit > 0 && len(entries) > filter.Limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取审计日志失败: %w", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// match 判断记录是否满足查询条件
+func (f Filter) match(entry Entry) bool {
+	switch {
+	case !f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && entry.Time.After(f.Until):
+		return false
+	case f.Session != "" && entry.Session != f.Session:
+		return false
+	case f.Client != "" && entry.Client != f.Client:
+		return false
+	case f.Instance != "" && entry.Instance != f.Instance:
+		return false
+	case f.Tool != "" && entry.Tool != f.Tool:
+		return false
+	case f.Method != "" && entry.Method != f.Method:
+		return false
+	case f.Failed && entry.Result != ResultError:
+		return false
+	case f.Allow != nil && !f.Allow(entry.Instance):
+		return false
+	}
+	if f.ObjectID != "" {
+		for _, id := range entry.ObjectIDs {
+			if id == f.ObjectID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
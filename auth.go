@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"zabbix-mcp-go/handler"
+	"zabbix-mcp-go/redact"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// httpAuth HTTP/SSE端点的认证：Bearer Token 或 mTLS客户端证书，二者满足其一即可
+type httpAuth struct {
+	clients []HTTPAuthClient
+	// anyCert 配置了客户端CA但没有客户端配置 cert_common_name，CA签发的证书都可以访问
+	anyCert bool
+	enabled bool
+}
+
+// authError 认证失败，status 为 401 或 403
+type authError struct {
+	status  int
+	message string
+}
+
+func (e *authError) Error() string {
+	return e.message
+}
+
+// newHTTPAuth 按配置创建认证器，并登记Token以便在日志中隐藏
+func newHTTPAuth(cfg HTTPConfig) *httpAuth {
+	auth := &httpAuth{
+		clients: cfg.Auth.Clients,
+		enabled: len(cfg.Auth.Clients) > 0 || cfg.TLS.ClientCAFile != "",
+	}
+	if cfg.TLS.ClientCAFile != "" {
+		auth.anyCert = true
+		for _, client := range cfg.Auth.Clients {
+			if client.CertCommonName != "" {
+				auth.anyCert = false
+			}
+		}
+	}
+	for _, client := range cfg.Auth.Clients {
+		redact.Register(client.Token)
+	}
+	return auth
+}
+
+// authenticate 识别请求的调用方。未启用认证时返回nil，表示不做任何限制
+func (a *httpAuth) authenticate(r *http.Request) (*handler.Principal, error) {
+	if !a.enabled {
+		return nil, nil
+	}
+
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return nil, &authError{http.StatusUnauthorized, "Authorization头格式错误，应为 Bearer <token>"}
+		}
+		for _, client := range a.clients {
+			if client.Token != "" && subtle.ConstantTimeCompare([]byte(client.Token), []byte(token)) == 1 {
+				return newPrincipal(client), nil
+			}
+		}
+		return nil, &authError{http.StatusUnauthorized, "Token无效"}
+	}
+
+	// 只有经过CA校验的证书才会出现在 VerifiedChains 中
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		if a.anyCert {
+			return &handler.Principal{Name: commonName}, nil
+		}
+		for _, client := range a.clients {
+			if client.CertCommonName != "" && client.CertCommonName == commonName {
+				return newPrincipal(client), nil
+			}
+		}
+		return nil, &authError{http.StatusForbidden, fmt.Sprintf("客户端证书未授权: %s", commonName)}
+	}
+
+	return nil, &authError{http.StatusUnauthorized, "缺少认证信息"}
+}
+
+// newPrincipal 将配置中的客户端转换为调用方
+func newPrincipal(client HTTPAuthClient) *handler.Principal {
+	return &handler.Principal{
+		Name:      client.Name,
+		Instances: client.Instances,
+		ReadOnly:  client.Role == RoleReadOnly,
+	}
+}
+
+// samePrincipal 判断两次请求是否来自同一调用方
+func samePrincipal(a, b *handler.Principal) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name
+}
+
+// writeAuthError 写入认证失败的响应并记录日志
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	authErr, ok := err.(*authError)
+	if !ok {
+		authErr = &authError{http.StatusUnauthorized, err.Error()}
+	}
+	GetSugar().Warnf("HTTP认证失败: %s %s 来自 %s: %s", r.Method, r.URL.Path, r.RemoteAddr, authErr.message)
+
+	if authErr.status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="zabbix-mcp"`)
+	}
+	writeJSONRPCError(w, authErr.status, mcp.INVALID_REQUEST, authErr.message)
+}
+
+// newServerTLSConfig 按配置创建服务端TLS配置，未配置证书时返回nil
+func newServerTLSConfig(cfg HTTPTLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取客户端CA证书失败: %w", err)
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("客户端CA证书中没有有效的PEM证书: %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = caPool
+		// 不强制要求证书，使用Token认证的客户端不需要提供证书
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"zabbix-mcp-go/zabbix"
+)
+
+// runCheckConfig 执行 check-config 子命令：校验配置文件，可选地探测每个实例的版本和登录。
+// 返回进程退出码，校验或探测失败时为1，便于在部署流水线中使用。
+func runCheckConfig(args []string) int {
+	fs := flag.NewFlagSet("check-config", flag.ContinueOnError)
+	var (
+		config  = fs.String("config", "", "配置文件路径，默认读取环境变量"+ConfigPathEnv+"，否则为"+DefaultConfigPath)
+		probe   = fs.Bool("probe", false, "连接每个实例，检测 apiinfo.version 并尝试登录")
+		timeout = fs.Duration("timeout", 10*time.Second, "探测单个实例的超时时间")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// 校验模式下只把日志输出到stderr，不创建日志文件
+	if err := InitCLILogger(); err != nil {
+		fmt.Fprintf(os.Stderr, "初始化日志失败: %v\n", err)
+		return 1
+	}
+	zabbix.SetLogger(GetSugar())
+	defer Sync()
+
+	out := os.Stdout
+	configPath := ResolveConfigPath(*config)
+	fmt.Fprintf(out, "配置文件: %s\n", configPath)
+
+	cfg, err := ReadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "配置校验通过，共 %d 个实例\n", len(cfg.Instances))
+
+	if !*probe {
+		return 0
+	}
+
+	failed := 0
+	for _, instance := range cfg.Instances {
+		if !probeInstance(out, instance, *timeout) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(out, "探测完成，%d/%d 个实例失败\n", failed, len(cfg.Instances))
+		return 1
+	}
+	fmt.Fprintf(out, "探测完成，所有实例均可用\n")
+	return 0
+}
+
+// probeInstance 探测单个实例的版本和登录，返回是否成功
+func probeInstance(out io.Writer, instance ZabbixInstance, timeout time.Duration) bool {
+	client, err := newZabbixClient(instance)
+	if err != nil {
+		fmt.Fprintf(out, "[FAIL] %s: 客户端配置错误: %v\n", instance.Name, err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	version, err := client.GetVersionContext(ctx)
+	if err != nil {
+		fmt.Fprintf(out, "[FAIL] %s: 版本检测失败: %v\n", instance.Name, err)
+		return false
+	}
+
+	if err := client.LoginContext(ctx); err != nil {
+		fmt.Fprintf(out, "[FAIL] %s: Zabbix %s，登录失败: %v\n", instance.Name, version.String(), err)
+		return false
+	}
+	// 密码认证每次登录都会创建会话，检查完成后登出；API token 不能登出
+	if client.AuthType != "token" {
+		defer client.LogoutContext(ctx)
+	}
+
+	// token认证的登录只调用了不需要认证的 apiinfo.version，用一次认证调用确认凭据确实有效
+	if _, err := client.CallContext(ctx, "host.get", map[string]interface{}{"output": []string{"hostid"}, "limit": 1}); err != nil {
+		fmt.Fprintf(out, "[FAIL] %s: Zabbix %s，认证失败: %v\n", instance.Name, version.String(), err)
+		return false
+	}
+
+	fmt.Fprintf(out, "[OK]   %s: Zabbix %s，登录成功\n", instance.Name, version.String())
+	return true
+}
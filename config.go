@@ -3,6 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -10,6 +15,114 @@ import (
 // Config 多实例配置
 type Config struct {
 	Instances []ZabbixInstance `yaml:"instances"`
+
+	// ReadOnly 只读模式：不注册也不允许调用任何修改Zabbix数据的工具
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// Transport 传输方式：stdio、sse 或 streamable-http，为空时同时启动stdio和SSE；
+	// 命令行参数 -transport、-stdio、-http 优先，修改后需要重启生效
+	Transport string `yaml:"transport,omitempty"`
+
+	// Log 日志输出配置，修改后需要重启生效
+	Log LogConfig `yaml:"log,omitempty"`
+
+	// Tracing 链路追踪配置，修改后需要重启生效
+	Tracing TracingConfig `yaml:"tracing,omitempty"`
+
+	// Audit 写操作审计日志配置，修改后需要重启生效
+	Audit AuditConfig `yaml:"audit,omitempty"`
+
+	// Health 后台健康检查配置，结果用于 /readyz，修改后需要重启生效
+	Health HealthConfig `yaml:"health,omitempty"`
+
+	// HTTP HTTP/SSE传输的TLS和认证配置，修改后需要重启生效
+	HTTP HTTPConfig `yaml:"http,omitempty"`
+}
+
+// 传输方式
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+)
+
+// HTTPConfig HTTP/SSE传输配置，命令行参数 -listen、-base-url、-base-path、-tls-cert、-tls-key 优先
+type HTTPConfig struct {
+	Listen          string         `yaml:"listen,omitempty"`           // 监听地址，如 ":5443" 或 "127.0.0.1:8080"，默认使用 -port 参数
+	BaseURL         string         `yaml:"base_url,omitempty"`         // 客户端访问本服务的外部地址，如 "https://mcp.example.com"，默认根据请求的Host生成
+	BasePath        string         `yaml:"base_path,omitempty"`        // 所有端点的路径前缀，如 "/zabbix"，此时SSE端点为 /zabbix/sse
+	ShutdownTimeout time.Duration  `yaml:"shutdown_timeout,omitempty"` // 收到SIGTERM后等待进行中请求完成的最长时间，默认10秒
+	TLS             HTTPTLSConfig  `yaml:"tls,omitempty"`
+	Auth            HTTPAuthConfig `yaml:"auth,omitempty"`
+}
+
+// HTTPTLSConfig HTTP/SSE服务端TLS配置，相对路径相对于配置文件所在目录
+type HTTPTLSConfig struct {
+	CertFile     string `yaml:"cert_file,omitempty"`      // 服务端证书（PEM），与 key_file 同时配置后使用HTTPS
+	KeyFile      string `yaml:"key_file,omitempty"`       // 服务端私钥（PEM）
+	ClientCAFile string `yaml:"client_ca_file,omitempty"` // 校验客户端证书的CA（PEM），配置后启用mTLS认证
+}
+
+// HTTPAuthConfig HTTP/SSE认证配置。clients 为空且未配置 tls.client_ca_file 时不认证
+type HTTPAuthConfig struct {
+	Clients []HTTPAuthClient `yaml:"clients,omitempty"`
+}
+
+// 客户端角色
+const (
+	RoleReadWrite = "read-write"
+	RoleReadOnly  = "read-only"
+)
+
+// HTTPAuthClient 允许访问HTTP/SSE端点的客户端，通过Bearer Token或客户端证书识别
+type HTTPAuthClient struct {
+	Name           string   `yaml:"name"`
+	Token          string   `yaml:"token,omitempty"`            // Bearer Token
+	TokenFile      string   `yaml:"token_file,omitempty"`       // 从文件读取Token，与 token 二选一
+	CertCommonName string   `yaml:"cert_common_name,omitempty"` // 客户端证书的CN，用于mTLS
+	Role           string   `yaml:"role,omitempty"`             // read-write 或 read-only，默认 read-write
+	Instances      []string `yaml:"instances,omitempty"`        // 允许访问的实例，为空表示所有实例
+
+	// tokenFromFile Token是从 token_file 读取的，Validate 据此判断是否同时配置了 token 和 token_file
+	tokenFromFile bool
+}
+
+// HealthConfig 后台健康检查配置
+type HealthConfig struct {
+	Interval time.Duration `yaml:"interval,omitempty"` // 检查间隔，如 "30s"，默认30秒
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // 单个实例的检查超时，如 "10s"，默认10秒
+}
+
+// TracingConfig OpenTelemetry链路追踪配置：每次工具调用为根span，每次Zabbix API调用为子span
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled,omitempty"`
+	Exporter    string  `yaml:"exporter,omitempty"`     // 导出方式：file、stdout、stderr 或 otlp，默认 file
+	File        string  `yaml:"file,omitempty"`         // exporter 为 file 时的输出文件，默认为日志目录下的 traces.jsonl；相对路径相对于配置文件所在目录
+	Endpoint    string  `yaml:"endpoint,omitempty"`     // exporter 为 otlp 时的OTLP/HTTP地址，如 "localhost:4318"，默认读取 OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure    bool    `yaml:"insecure,omitempty"`     // OTLP使用HTTP而不是HTTPS
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"` // 采样比例，取值(0, 1]，默认1即全部采样
+	ServiceName string  `yaml:"service_name,omitempty"` // 上报的服务名，默认 zabbix-mcp-server
+}
+
+// AuditConfig 审计日志配置：通过本服务执行的每次Zabbix写操作都以JSON lines追加到单独的文件，不切分也不清理
+type AuditConfig struct {
+	Disable bool   `yaml:"disable,omitempty"` // 不记录审计日志
+	File    string `yaml:"file,omitempty"`    // 审计日志文件，默认为日志目录下的 audit.jsonl；相对路径相对于配置文件所在目录
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level       string `yaml:"level,omitempty"`        // 日志级别：debug、info、warn、error，默认 info
+	Format      string `yaml:"format,omitempty"`       // 输出格式：json 或 console，默认 json
+	Console     string `yaml:"console,omitempty"`      // 控制台输出位置：stderr、stdout 或 none，默认 stderr；stdio传输方式下不会写stdout
+	DisableFile bool   `yaml:"disable_file,omitempty"` // 不写日志文件，只输出到控制台
+
+	// 日志文件的目录、切分和保留策略
+	Dir        string `yaml:"dir,omitempty"`          // 日志目录，默认 logs；相对路径相对于配置文件所在目录
+	MaxSizeMB  int    `yaml:"max_size_mb,omitempty"`  // 单个文件最大MB数，超过后当天切分出新文件，0表示不限制
+	MaxAgeDays int    `yaml:"max_age_days,omitempty"` // 历史文件保留天数，0表示不按时间清理
+	MaxBackups int    `yaml:"max_backups,omitempty"`  // 历史文件保留个数，0表示不按个数清理
+	Compress   bool   `yaml:"compress,omitempty"`     // 是否gzip压缩历史文件
 }
 
 // ZabbixInstance Zabbix实例配置
@@ -21,19 +134,271 @@ type ZabbixInstance struct {
 	Token    string `yaml:"token,omitempty"`
 	AuthType string `yaml:"auth_type,omitempty"` // "password" 或 "token"
 	Default  bool   `yaml:"default,omitempty"`
+
+	// Required 为 true 时该实例必须健康 /readyz 才返回就绪；没有任何实例设置时，至少一个实例健康即就绪
+	Required bool `yaml:"required,omitempty"`
+
+	// PasswordFile/TokenFile 从文件读取密码或token（例如挂载的Kubernetes Secret），
+	// 相对路径相对于配置文件所在目录
+	PasswordFile string `yaml:"password_file,omitempty"`
+	TokenFile    string `yaml:"token_file,omitempty"`
+
+	// passwordFromFile/tokenFromFile 密码或token是从文件读取的，Validate 据此判断是否与 password/token 同时配置
+	passwordFromFile bool
+	tokenFromFile    bool
+
+	// Retry 只读API调用的重试策略，不配置时使用默认策略
+	Retry *RetryConfig `yaml:"retry,omitempty"`
+
+	// CircuitBreaker 熔断器配置，不配置时使用默认参数
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker,omitempty"`
+
+	// TLS HTTPS连接配置（自定义CA、客户端证书等），不配置时使用系统默认
+	TLS *TLSConfig `yaml:"tls,omitempty"`
+
+	// ProxyURL 访问Zabbix API使用的代理，支持 http://、https://、socks5://
+	ProxyURL string `yaml:"proxy_url,omitempty"`
+
+	// ExtraHeaders 每个API请求附带的额外HTTP头部，例如反向代理要求的 X-Api-Key
+	ExtraHeaders map[string]string `yaml:"extra_headers,omitempty"`
+
+	// Tools 该实例允许调用的工具，不配置时允许所有工具（全局 read_only 仍然生效）
+	Tools *ToolsConfig `yaml:"tools,omitempty"`
 }
 
+// RetryConfig 重试策略配置
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`         // 最大尝试次数（含首次），1表示不重试
+	BaseDelay   time.Duration `yaml:"base_delay,omitempty"` // 基础等待时间，如 "500ms"
+	MaxDelay    time.Duration `yaml:"max_delay,omitempty"`  // 单次等待时间上限，如 "5s"
+}
+
+// ToolsConfig 实例的工具白名单和黑名单。配置了 allow 时只允许其中的工具，deny 中的工具总是禁止
+type ToolsConfig struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold,omitempty"` // 连续失败多少次后熔断
+	OpenTimeout      time.Duration `yaml:"open_timeout,omitempty"`      // 熔断后多久进行探测，如 "30s"
+}
+
+// TLSConfig 实例TLS配置，相对路径相对于配置文件所在目录
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file,omitempty"`              // 自定义CA证书路径（PEM）
+	CertFile           string `yaml:"cert_file,omitempty"`            // 客户端证书路径（PEM），用于mTLS
+	KeyFile            string `yaml:"key_file,omitempty"`             // 客户端私钥路径（PEM），用于mTLS
+	ServerName         string `yaml:"server_name,omitempty"`          // 覆盖证书校验使用的服务器名称
+	MinVersion         string `yaml:"min_version,omitempty"`          // 最低TLS版本，如 "1.2"
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"` // 跳过证书校验，仅用于测试
+}
+
+// DefaultConfigPath 默认配置文件路径
+const DefaultConfigPath = "config.yaml"
+
+// ConfigPathEnv 指定配置文件路径的环境变量
+const ConfigPathEnv = "ZABBIX_MCP_CONFIG"
+
 var AppConfig Config
 
-func LoadConfig() error {
-	data, err := os.ReadFile("config.yaml")
+// appConfigMu 保护 AppConfig：热加载在后台替换配置，HTTP处理函数并发读取
+var appConfigMu sync.RWMutex
+
+// CurrentConfig 返回当前配置，可在任意goroutine中调用
+func CurrentConfig() Config {
+	appConfigMu.RLock()
+	defer appConfigMu.RUnlock()
+	return AppConfig
+}
+
+// setAppConfig 替换当前配置
+func setAppConfig(config Config) {
+	appConfigMu.Lock()
+	defer appConfigMu.Unlock()
+	AppConfig = config
+}
+
+// ConfigPath 当前使用的配置文件路径
+var ConfigPath string
+
+// ResolveConfigPath 确定配置文件路径：命令行参数优先，其次环境变量，最后使用默认路径
+func ResolveConfigPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if envValue := os.Getenv(ConfigPathEnv); envValue != "" {
+		return envValue
+	}
+	return DefaultConfigPath
+}
+
+// LoadConfig 读取、解析并校验配置文件，成功后写入 AppConfig
+func LoadConfig(path string) error {
+	config, err := ReadConfig(path)
 	if err != nil {
-		return fmt.Errorf("读取配置文件失败: %w", err)
+		return err
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	setAppConfig(*config)
+	ConfigPath = path
+	return nil
+}
+
+// ReadConfig 读取配置文件，展开 ${ENV_VAR} 环境变量引用，并从 password_file/token_file 读取密钥
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	// 只展开YAML中的值，注释中的 ${VAR} 不受影响，展开后的特殊字符也不会破坏YAML结构
+	if err := expandEnvInNode(&root); err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	baseDir := filepath.Dir(path)
+	for i := range config.Instances {
+		if err := loadSecretFiles(&config.Instances[i], baseDir); err != nil {
+			return nil, err
+		}
+		if tls := config.Instances[i].TLS; tls != nil {
+			for _, path := range []*string{&tls.CAFile, &tls.CertFile, &tls.KeyFile} {
+				if *path != "" && !filepath.IsAbs(*path) {
+					*path = filepath.Join(baseDir, *path)
+				}
+			}
+		}
+	}
+
+	// 日志目录相对于配置文件所在目录，不受启动时工作目录影响
+	if config.Log.Dir == "" {
+		config.Log.Dir = "logs"
+	}
+	if !filepath.IsAbs(config.Log.Dir) {
+		config.Log.Dir = filepath.Join(baseDir, config.Log.Dir)
+	}
+	if config.Tracing.File != "" && !filepath.IsAbs(config.Tracing.File) {
+		config.Tracing.File = filepath.Join(baseDir, config.Tracing.File)
+	}
+	if config.Audit.File != "" && !filepath.IsAbs(config.Audit.File) {
+		config.Audit.File = filepath.Join(baseDir, config.Audit.File)
 	}
 
-	if err := yaml.Unmarshal(data, &AppConfig); err != nil {
-		return fmt.Errorf("解析配置文件失败: %w", err)
+	for _, path := range []*string{&config.HTTP.TLS.CertFile, &config.HTTP.TLS.KeyFile, &config.HTTP.TLS.ClientCAFile} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(baseDir, *path)
+		}
 	}
+	for i := range config.HTTP.Auth.Clients {
+		client := &config.HTTP.Auth.Clients[i]
+		// 同时配置了 token 时不读取文件，由 Validate 报告冲突
+		if client.TokenFile == "" || client.Token != "" {
+			continue
+		}
+		secret, err := readSecretFile(client.TokenFile, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("客户端 %s 读取 token_file 失败: %w", client.Name, err)
+		}
+		client.Token = secret
+		client.tokenFromFile = true
+	}
+
+	return &config, nil
+}
 
+// envRefPattern 匹配 ${VAR} 和 ${VAR:-default}
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnvInNode 展开YAML节点树中所有标量值的 ${VAR} 引用；未设置且没有默认值的变量会一起报错
+func expandEnvInNode(root *yaml.Node) error {
+	var missing []string
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.ScalarNode {
+			expanded := expandEnv(node.Value, &missing)
+			// 未加引号的值展开后清除解析时得到的 !!str 标签，由解码时重新识别为数字或布尔值
+			if expanded != node.Value && node.Style == 0 {
+				node.Tag = ""
+			}
+			node.Value = expanded
+			return
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	if len(missing) > 0 {
+		return fmt.Errorf("配置文件引用的环境变量未设置: %s", strings.Join(missing, ", "))
+	}
 	return nil
 }
+
+// expandEnv 展开文本中的 ${VAR} 引用，未设置且没有默认值的变量名追加到missing
+func expandEnv(text string, missing *[]string) string {
+	return envRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		match := envRefPattern.FindStringSubmatch(ref)
+		name, hasDefault, defaultValue := match[1], match[2] != "", match[3]
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		if hasDefault {
+			return defaultValue
+		}
+		*missing = append(*missing, name)
+		return ""
+	})
+}
+
+// loadSecretFiles 从 password_file/token_file 读取密钥，去掉首尾空白（Secret文件通常以换行结尾）。
+// 同时配置了 password/token 时不读取文件，由 Validate 报告冲突
+func loadSecretFiles(instance *ZabbixInstance, baseDir string) error {
+	if instance.PasswordFile != "" && instance.Pass == "" {
+		secret, err := readSecretFile(instance.PasswordFile, baseDir)
+		if err != nil {
+			return fmt.Errorf("实例 %s 读取 password_file 失败: %w", instance.Name, err)
+		}
+		instance.Pass = secret
+		instance.passwordFromFile = true
+	}
+
+	if instance.TokenFile != "" && instance.Token == "" {
+		secret, err := readSecretFile(instance.TokenFile, baseDir)
+		if err != nil {
+			return fmt.Errorf("实例 %s 读取 token_file 失败: %w", instance.Name, err)
+		}
+		instance.Token = secret
+		instance.tokenFromFile = true
+	}
+
+	return nil
+}
+
+// readSecretFile 读取密钥文件，相对路径相对于配置文件所在目录
+func readSecretFile(path, baseDir string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
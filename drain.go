@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// drainGuard HTTP传输的优雅退出：shutdown 后拒绝新的请求，等待进行中的请求处理完成，再通知长连接结束
+type drainGuard struct {
+	closing  atomic.Bool
+	inflight sync.RWMutex
+	closed   chan struct{}
+}
+
+// newDrainGuard 创建退出控制
+func newDrainGuard() *drainGuard {
+	return &drainGuard{closed: make(chan struct{})}
+}
+
+// acquire 开始处理一个请求，正在退出时返回false；返回true时处理完成后需要调用 release
+func (g *drainGuard) acquire() bool {
+	if g.closing.Load() {
+		return false
+	}
+	g.inflight.RLock()
+	if g.closing.Load() {
+		g.inflight.RUnlock()
+		return false
+	}
+	return true
+}
+
+// release 请求处理完成
+func (g *drainGuard) release() {
+	g.inflight.RUnlock()
+}
+
+// isClosing 是否正在退出
+func (g *drainGuard) isClosing() bool {
+	return g.closing.Load()
+}
+
+// done 返回长连接需要结束时关闭的channel
+func (g *drainGuard) done() <-chan struct{} {
+	return g.closed
+}
+
+// shutdown 停止接受新的请求，等待进行中的请求处理完成（最长到ctx结束）后通知长连接结束。
+// 超时返回false。
+func (g *drainGuard) shutdown(ctx context.Context) bool {
+	g.closing.Store(true)
+
+	drained := make(chan struct{})
+	go func() {
+		g.inflight.Lock()
+		close(drained)
+		g.inflight.Unlock()
+	}()
+
+	ok := true
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		ok = false
+	}
+	close(g.closed)
+	return ok
+}
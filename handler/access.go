@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Principal 通过HTTP认证的调用方，stdio和未启用认证时为nil，不做任何限制
+type Principal struct {
+	Name      string
+	Instances []string // 允许访问的实例，为空表示所有实例
+	ReadOnly  bool     // 只能调用只读工具
+}
+
+// CanAccess 判断调用方是否可以访问指定实例
+func (p *Principal) CanAccess(instanceName string) bool {
+	if p == nil || len(p.Instances) == 0 {
+		return true
+	}
+	for _, name := range p.Instances {
+		if name == instanceName {
+			return true
+		}
+	}
+	return false
+}
+
+// principalKey ctx中保存调用方的键
+type principalKey struct{}
+
+// WithPrincipal 将认证后的调用方保存到ctx，由传输层在处理每条消息前调用
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext 获取ctx中的调用方，没有时返回nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// toolAccess 工具的访问属性
+type toolAccess struct {
+	readOnly bool // 不修改Zabbix中的数据
+	global   bool // 未指定实例时不访问具体实例，由处理函数按调用方过滤结果
+}
+
+// toolAccessRules 各工具的访问属性，未列出的工具按需要写权限处理
+var toolAccessRules = map[string]toolAccess{
+	"get_hosts":          {readOnly: true},
+	"get_host_by_name":   {readOnly: true},
+	"create_host":        {},
+	"delete_host":        {},
+	"get_host_items":     {readOnly: true},
+	"get_item_data":      {readOnly: true},
+	"create_item":        {},
+	"get_triggers":       {readOnly: true},
+	"get_trigger_events": {readOnly: true},
+	"acknowledge_event":  {},
+	"get_templates":      {readOnly: true},
+	"get_host_templates": {readOnly: true},
+	"link_template":      {},
+	"unlink_template":    {},
+	"list_instances":     {readOnly: true, global: true},
+	"switch_instance":    {readOnly: true},
+	"get_instances_info": {readOnly: true, global: true},
+	"get_audit_log":      {readOnly: true, global: true},
+}
+
+// authorize 包装工具处理函数，调用前检查工具访问策略，以及调用方的角色和可访问的实例。
+// 策略禁止的工具在注册时已经去掉，这里再检查一次，防止配置热加载后策略变化或实例级别的限制被绕过
+func authorize(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	access := toolAccessRules[name]
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		policy := currentToolPolicy()
+		principal := PrincipalFromContext(ctx)
+
+		if policy.ReadOnly && !access.readOnly {
+			GetSugar().Warnf("拒绝工具调用: 只读模式下不能调用 %s", name)
+			return permissionDenied("权限不足: 只读模式下不能调用 %s", name), nil
+		}
+		if principal != nil && principal.ReadOnly && !access.readOnly {
+			GetSugar().Warnf("拒绝工具调用: 客户端 %s 为只读角色，不能调用 %s", principal.Name, name)
+			return permissionDenied("权限不足: 只读角色不能调用 %s", name), nil
+		}
+
+		instanceName, _ := req.Params.Arguments["instance"].(string)
+		if instanceName == "" && access.global {
+			return handler(ctx, req)
+		}
+		instanceName = resolveInstance(ctx, instanceName)
+		if instanceName == "" {
+			instanceName = pool.GetDefaultInstanceName()
+		}
+		if !policy.allows(name, instanceName) {
+			GetSugar().Warnf("拒绝工具调用: 实例 %s 不允许调用 %s", instanceName, name)
+			return permissionDenied("权限不足: 实例 %s 不允许调用 %s", instanceName, name), nil
+		}
+		if !principal.CanAccess(instanceName) {
+			GetSugar().Warnf("拒绝工具调用: 客户端 %s 无权访问实例 %s", principal.Name, instanceName)
+			return permissionDenied("权限不足: 无权访问实例 %s", instanceName), nil
+		}
+		return handler(ctx, req)
+	}
+}
+
+// filterInstances 只保留调用方可以访问的实例
+func filterInstances(ctx context.Context, instances []map[string]interface{}) []map[string]interface{} {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || len(principal.Instances) == 0 {
+		return instances
+	}
+	allowed := make([]map[string]interface{}, 0, len(instances))
+	for _, instance := range instances {
+		if name, ok := instance["name"].(string); ok && principal.CanAccess(name) {
+			allowed = append(allowed, instance)
+		}
+	}
+	return allowed
+}
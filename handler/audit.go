@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+	"zabbix-mcp-go/audit"
+	"zabbix-mcp-go/redact"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetAuditLogHandler 查询审计日志，只返回调用方可以访问的实例的记录
+func GetAuditLogHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	GetSugar().Infof("调用GetAuditLogHandler，参数: %+v", redact.Args(req.Params.Arguments))
+
+	args := req.Params.Arguments
+	filter := audit.Filter{Limit: 50}
+
+	if v, ok := args["instance"].(string); ok {
+		filter.Instance = v
+	}
+	if v, ok := args["session"].(string); ok {
+		filter.Session = v
+	}
+	if v, ok := args["client"].(string); ok {
+		filter.Client = v
+	}
+	if v, ok := args["tool"].(string); ok {
+		filter.Tool = v
+	}
+	if v, ok := args["method"].(string); ok {
+		filter.Method = v
+	}
+	if v, ok := args["object_id"].(string); ok {
+		filter.ObjectID = v
+	}
+	if v, ok := args["failed_only"].(bool); ok {
+		filter.Failed = v
+	}
+	if v, ok := args["time_range"].(string); ok && v != "" {
+		duration, err := parseRangeDuration(v)
+		if err != nil {
+			return validationError("%v", err), nil
+		}
+		filter.Since = time.Now().Add(-duration)
+	}
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		filter.Limit = int(math.Min(v, 500))
+	}
+
+	principal := PrincipalFromContext(ctx)
+	filter.Allow = principal.CanAccess
+
+	entries, err := audit.Query(filter)
+	if err != nil {
+		GetSugar().Errorf("查询审计日志失败: %v", err)
+		return internalError("查询审计日志失败", err), nil
+	}
+
+	GetSugar().Infof("成功查询审计日志，共 %d 条记录", len(entries))
+
+	resultData, _ := json.Marshal(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+	return mcp.NewToolResultText(string(resultData)), nil
+}
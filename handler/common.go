@@ -1,6 +1,16 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"zabbix-mcp-go/redact"
+	"zabbix-mcp-go/zabbix"
+
+	"github.com/mark3labs/mcp-go/mcp"
 	"go.uber.org/zap"
 )
 
@@ -19,35 +29,44 @@ type ClientPool interface {
 	GetClient(instanceName string) interface{}
 	ListInstances() []map[string]interface{}
 	SetDefault(instanceName string) error
+	GetDefaultInstanceName() string
+	GetAllInstancesInfoContext(ctx context.Context) map[string]interface{}
 }
 
-// ZabbixClient 接口定义
+// ZabbixClient 接口定义，所有方法都接收ctx，以便MCP客户端取消工具调用时中止Zabbix请求
 type ZabbixClient interface {
 	// 主机相关
-	GetHostsWithPagination(groupID, hostName string, page, pageSize int) ([]map[string]interface{}, int, error)
-	GetHosts(groupID, hostName string) ([]map[string]interface{}, error)
-	GetHostByName(hostName string) (map[string]interface{}, error)
-	GetHostByNameLite(hostName string) (map[string]interface{}, error)
-	CreateHost(hostName, groupID, interfaceIP string) (string, error)
-	DeleteHost(hostID string) error
+	GetHostsWithPaginationContext(ctx context.Context, groupID, hostName string, page, pageSize int) ([]map[string]interface{}, int, error)
+	GetHostsContext(ctx context.Context, groupID, hostName string) ([]map[string]interface{}, error)
+	GetHostByNameContext(ctx context.Context, hostName string) (map[string]interface{}, error)
+	GetHostByNameLiteContext(ctx context.Context, hostName string) (map[string]interface{}, error)
+	CreateHostContext(ctx context.Context, hostName, groupID, interfaceIP string) (string, error)
+	DeleteHostContext(ctx context.Context, hostID string) error
+	GetHostDeletePreviewContext(ctx context.Context, hostID string) (map[string]interface{}, error)
 
 	// 监控项相关
-	GetItems(hostID, itemNameFilter string) ([]map[string]interface{}, error)
-	GetItemInfo(itemID string) (map[string]interface{}, error)
-	GetItemData(itemID string, history, limit int) ([]map[string]interface{}, error)
-	GetItemDataWithTimeRange(itemID string, history int, timeFrom, timeTill string) ([]map[string]interface{}, error)
-	CreateItem(hostID, itemName, key, itemType, valueType, delay string) (string, error)
+	GetItemsContext(ctx context.Context, hostID, itemNameFilter string) ([]map[string]interface{}, error)
+	GetItemInfoContext(ctx context.Context, itemID string) (map[string]interface{}, error)
+	GetItemDataContext(ctx context.Context, itemID string, history, limit int) ([]map[string]interface{}, error)
+	GetItemDataWithTimeRangeContext(ctx context.Context, itemID string, history int, timeFrom, timeTill string) ([]map[string]interface{}, error)
+	GetItemWithHistoryContext(ctx context.Context, itemID string, history int, timeFrom, timeTill string) (map[string]interface{}, []map[string]interface{}, error)
+	CreateItemContext(ctx context.Context, hostID, itemName, key, itemType, valueType, delay string) (string, error)
 
 	// 触发器相关
-	GetTriggers(hostID string, active bool) ([]map[string]interface{}, error)
-	GetTriggerEvents(triggerID string, limit int) ([]map[string]interface{}, error)
-	MassAcknowledgeEvents(eventIDs []string, message string) error
+	GetTriggersContext(ctx context.Context, hostID string, active bool) ([]map[string]interface{}, error)
+	GetTriggerEventsContext(ctx context.Context, triggerID string, limit int) ([]map[string]interface{}, error)
+	GetEventsContext(ctx context.Context, params map[string]interface{}) ([]map[string]interface{}, error)
+	MassAcknowledgeEventsContext(ctx context.Context, eventIDs []string, message string) error
 
 	// 模板相关
-	GetTemplates() ([]map[string]interface{}, error)
-	GetTemplatesByHost(hostID string) ([]map[string]interface{}, error)
-	LinkTemplates(hostID string, templateIDs []string) error
-	UnlinkTemplates(hostID string, templateIDs []string, clear bool) error
+	GetTemplatesContext(ctx context.Context) ([]map[string]interface{}, error)
+	GetTemplatesByHostContext(ctx context.Context, hostID string) ([]map[string]interface{}, error)
+	LinkTemplatesContext(ctx context.Context, hostID string, templateIDs []string) error
+	UnlinkTemplatesContext(ctx context.Context, hostID string, templateIDs []string, clear bool) error
+	GetTemplateUnlinkPreviewContext(ctx context.Context, hostID string, templateIDs []string) ([]map[string]interface{}, error)
+
+	// 实例相关
+	GetInstanceInfoContext(ctx context.Context) map[string]interface{}
 }
 
 // SetDependencies 设置依赖项，由主程序调用
@@ -70,3 +89,160 @@ func MustJSON(v interface{}) string {
 	}
 	return ""
 }
+
+// 工具错误类别
+const (
+	ErrorNotFound   = "not_found"  // 实例或Zabbix对象不存在
+	ErrorAuth       = "auth"       // 本服务登录Zabbix失败，需要管理员检查实例凭据
+	ErrorPermission = "permission" // 调用方或Zabbix账号无权执行该操作
+	ErrorValidation = "validation" // 参数错误，修改参数后可以重试
+	ErrorUpstream   = "upstream"   // Zabbix不可用或返回了其他错误
+	ErrorInternal   = "internal"   // 本服务内部错误
+)
+
+// toolError 工具调用失败的原因，以JSON作为 IsError 结果的内容返回，
+// 客户端（LLM）可以据此判断是修改参数、换一个实例还是稍后重试
+type toolError struct {
+	Category  string `json:"category"`
+	Message   string `json:"message"`
+	Code      int    `json:"code,omitempty"` // Zabbix API错误码
+	Data      string `json:"data,omitempty"` // Zabbix API错误详情
+	Retryable bool   `json:"retryable"`      // 相同参数稍后重试可能成功
+	Hint      string `json:"hint,omitempty"`
+}
+
+// Error 实现error接口
+func (e *toolError) Error() string {
+	return e.Message
+}
+
+// errorResult 将错误转换为 IsError 的工具结果。action 描述失败的操作，如 "获取主机失败"，为空时只使用错误本身的描述。
+// 处理函数出错时应返回 (errorResult(...), nil)，而不是返回error：mcp-go 会把error变成JSON-RPC协议错误，
+// 很多客户端不会把它交给LLM
+func errorResult(action string, err error) *mcp.CallToolResult {
+	toolErr := classifyError(err)
+	if action != "" {
+		toolErr.Message = action + ": " + toolErr.Message
+	}
+	toolErr.Message = redact.String(toolErr.Message)
+
+	data, _ := json.Marshal(toolErr)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(data),
+			},
+		},
+		IsError: true,
+	}
+}
+
+// validationError 参数错误的工具结果
+func validationError(format string, args ...interface{}) *mcp.CallToolResult {
+	return errorResult("", &toolError{
+		Category: ErrorValidation,
+		Message:  fmt.Sprintf(format, args...),
+		Hint:     "检查参数后重新调用",
+	})
+}
+
+// permissionDenied 调用方无权调用工具或访问实例的工具结果
+func permissionDenied(format string, args ...interface{}) *mcp.CallToolResult {
+	return errorResult("", &toolError{
+		Category: ErrorPermission,
+		Message:  fmt.Sprintf(format, args...),
+		Hint:     "该操作被服务端配置禁止，重试不会成功",
+	})
+}
+
+// internalError 本服务内部错误的工具结果，例如序列化结果失败
+func internalError(action string, err error) *mcp.CallToolResult {
+	return errorResult(action, &toolError{
+		Category: ErrorInternal,
+		Message:  err.Error(),
+	})
+}
+
+// instanceNotFound 实例不存在的工具结果
+func instanceNotFound(instanceName string) *mcp.CallToolResult {
+	message := "未找到指定的实例"
+	if instanceName != "" {
+		message += ": " + instanceName
+	}
+	return errorResult("", &toolError{
+		Category: ErrorNotFound,
+		Message:  message,
+		Hint:     "使用 list_instances 查看可用的实例",
+	})
+}
+
+// classifyError 根据错误类型确定类别和重试提示，Zabbix API错误附带错误码和详情
+func classifyError(err error) *toolError {
+	var toolErr *toolError
+	if errors.As(err, &toolErr) {
+		copied := *toolErr
+		return &copied
+	}
+
+	result := &toolError{Category: ErrorUpstream, Message: err.Error()}
+
+	var rpcErr *zabbix.RPCError
+	var notFoundErr *zabbix.NotFoundError
+	var statusErr *zabbix.HTTPStatusError
+	switch {
+	case errors.As(err, &rpcErr):
+		result.Code = rpcErr.Code
+		result.Data = rpcErr.Data
+		classifyRPCError(rpcErr, result)
+	case errors.As(err, &notFoundErr):
+		result.Category = ErrorNotFound
+		result.Hint = "确认ID或名称是否正确，可以先用查询类工具查找"
+	case errors.Is(err, zabbix.ErrCircuitOpen):
+		result.Retryable = true
+		result.Hint = "该实例连续请求失败，暂时停止访问，稍后重试或使用其他实例"
+	case errors.Is(err, context.Canceled):
+		result.Hint = "调用已被取消"
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden):
+		result.Category = ErrorAuth
+		result.Hint = "Zabbix前端或反向代理拒绝了请求，需要管理员检查实例配置"
+	case zabbix.IsRetryableError(err) || errors.Is(err, context.DeadlineExceeded):
+		result.Retryable = true
+		result.Hint = "Zabbix暂时不可用或响应超时，可以稍后重试"
+	default:
+		result.Hint = "Zabbix返回了错误，重试前请先检查错误信息"
+	}
+	return result
+}
+
+// classifyRPCError 根据Zabbix API错误码和详情确定类别
+func classifyRPCError(rpcErr *zabbix.RPCError, result *toolError) {
+	detail := strings.ToLower(rpcErr.Message + " " + rpcErr.Data)
+	switch {
+	case strings.Contains(detail, "not authorized") || strings.Contains(detail, "not authorised") ||
+		strings.Contains(detail, "session terminated") || strings.Contains(detail, "re-login") ||
+		strings.Contains(detail, "incorrect user name or password") || strings.Contains(detail, "api token expired"):
+		result.Category = ErrorAuth
+		result.Hint = "本服务登录Zabbix失败，需要管理员检查实例的用户名密码或API token"
+	case strings.Contains(detail, "does not exist"):
+		// Zabbix对不存在的对象和无权访问的对象返回相同的错误
+		result.Category = ErrorNotFound
+		result.Hint = "对象不存在，或实例配置的Zabbix账号无权访问该对象"
+	case strings.Contains(detail, "permission"):
+		result.Category = ErrorPermission
+		result.Hint = "实例配置的Zabbix账号无权执行该操作"
+	case strings.Contains(detail, "already exists"):
+		result.Category = ErrorValidation
+		result.Hint = "对象已存在，换一个名称或先查询已有对象"
+	case rpcErr.Code == -32602:
+		result.Category = ErrorValidation
+		result.Hint = "Zabbix拒绝了请求参数，检查参数后重新调用"
+	case rpcErr.Code == -32601:
+		result.Hint = "当前Zabbix版本不支持该API方法"
+	case rpcErr.Code == -32603:
+		result.Retryable = true
+		result.Hint = "Zabbix内部错误，可以稍后重试"
+	default:
+		result.Hint = "Zabbix返回了错误，重试前请先检查错误信息"
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// confirmTokenTTL 确认令牌的有效期，过期后需要重新预览
+const confirmTokenTTL = 5 * time.Minute
+
+// pendingChange 已经预览、等待确认执行的修改。令牌只能在同一会话中、由同一调用方、
+// 以相同参数对同一实例调用同一工具时使用一次
+type pendingChange struct {
+	tool      string
+	instance  string
+	sessionID string
+	principal string
+	digest    string
+	expires   time.Time
+}
+
+var (
+	pendingChanges   = make(map[string]pendingChange)
+	pendingChangesMu sync.Mutex
+)
+
+// confirmChange 破坏性工具的预览和确认流程，由处理函数在执行修改前调用：
+//   - dry_run 为 true，或需要确认但没有带 confirm_token 时，调用 preview 获取将要发生的修改，
+//     返回预览结果和确认令牌，不执行修改；
+//   - 带有效的 confirm_token 时返回nil，令牌随即失效，由处理函数继续执行修改；
+//   - required 为 false 且不是 dry_run 时直接返回nil；
+//   - 令牌无效或预览失败时返回 IsError 的结果。
+func confirmChange(ctx context.Context, tool string, req mcp.CallToolRequest, required bool, preview func() (map[string]interface{}, error)) *mcp.CallToolResult {
+	args := req.Params.Arguments
+	dryRun, _ := args["dry_run"].(bool)
+	token, _ := args["confirm_token"].(string)
+	instanceArg, _ := args["instance"].(string)
+	instanceName := targetInstance(ctx, instanceArg)
+
+	if !dryRun {
+		if token != "" {
+			if err := consumeConfirmToken(ctx, tool, instanceName, token, args); err != nil {
+				GetSugar().Warnf("%s 确认失败: %v", tool, err)
+				return errorResult("", err)
+			}
+			GetSugar().Infof("%s 已确认，开始执行 - 实例: %s", tool, instanceName)
+			return nil
+		}
+		if !required {
+			return nil
+		}
+	}
+
+	changes, err := preview()
+	if err != nil {
+		GetSugar().Errorf("%s 预览失败: %v", tool, err)
+		return errorResult("", err)
+	}
+
+	result := map[string]interface{}{
+		"executed": false,
+		"instance": instanceName,
+		"changes":  changes,
+	}
+	if required {
+		token, expires, err := issueConfirmToken(ctx, tool, instanceName, args)
+		if err != nil {
+			GetSugar().Errorf("%s 生成确认令牌失败: %v", tool, err)
+			return internalError("生成确认令牌失败", err)
+		}
+		result["confirm_token"] = token
+		result["expires_at"] = expires.Format(time.RFC3339)
+		result["message"] = fmt.Sprintf("尚未执行。确认以上修改无误后，使用相同参数并带上 confirm_token 再次调用 %s 执行，令牌 %v 内有效且只能使用一次", tool, confirmTokenTTL)
+	} else {
+		result["message"] = fmt.Sprintf("尚未执行。该操作不需要确认，去掉 dry_run 再次调用 %s 即可执行", tool)
+	}
+	GetSugar().Infof("%s 预览完成，未执行修改 - 实例: %s", tool, instanceName)
+
+	resultData, _ := json.Marshal(result)
+	return mcp.NewToolResultText(string(resultData))
+}
+
+// issueConfirmToken 生成确认令牌并记录对应的修改，同时清理已过期的令牌
+func issueConfirmToken(ctx context.Context, tool, instanceName string, args map[string]interface{}) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(buf)
+	now := time.Now()
+	change := pendingChange{
+		tool:      tool,
+		instance:  instanceName,
+		sessionID: SessionID(ctx),
+		principal: principalName(ctx),
+		digest:    argsDigest(args),
+		expires:   now.Add(confirmTokenTTL),
+	}
+
+	pendingChangesMu.Lock()
+	defer pendingChangesMu.Unlock()
+	for t, c := range pendingChanges {
+		if now.After(c.expires) {
+			delete(pendingChanges, t)
+		}
+	}
+	pendingChanges[token] = change
+	return token, change.expires, nil
+}
+
+// consumeConfirmToken 校验确认令牌，无论是否通过令牌都会失效，避免被反复尝试
+func consumeConfirmToken(ctx context.Context, tool, instanceName, token string, args map[string]interface{}) error {
+	pendingChangesMu.Lock()
+	change, exists := pendingChanges[token]
+	delete(pendingChanges, token)
+	pendingChangesMu.Unlock()
+
+	if !exists || time.Now().After(change.expires) {
+		return confirmTokenError("确认令牌无效或已过期")
+	}
+	if change.tool != tool || change.instance != instanceName ||
+		change.sessionID != SessionID(ctx) || change.principal != principalName(ctx) {
+		return confirmTokenError("确认令牌不属于本次调用")
+	}
+	if change.digest != argsDigest(args) {
+		return confirmTokenError("参数与预览时不一致")
+	}
+	return nil
+}
+
+// confirmTokenError 确认令牌校验失败的错误，修改没有执行
+func confirmTokenError(message string) error {
+	return &toolError{
+		Category: ErrorValidation,
+		Message:  message + "，修改未执行",
+		Hint:     "先使用 dry_run 重新预览，再带上新的 confirm_token 调用",
+	}
+}
+
+// dropConfirmTokens 会话结束时清理该会话的确认令牌
+func dropConfirmTokens(sessionID string) {
+	pendingChangesMu.Lock()
+	defer pendingChangesMu.Unlock()
+	for token, change := range pendingChanges {
+		if change.sessionID == sessionID {
+			delete(pendingChanges, token)
+		}
+	}
+}
+
+// argsDigest 计算除实例、dry_run 和 confirm_token 以外的参数摘要，用于确认时比对参数是否改变
+func argsDigest(args map[string]interface{}) string {
+	filtered := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		switch key {
+		case "instance", "dry_run", "confirm_token":
+			continue
+		}
+		filtered[key] = value
+	}
+	// map 的键按字母顺序序列化，结果是确定的
+	data, _ := json.Marshal(filtered)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// targetInstance 返回本次调用实际使用的实例名称
+func targetInstance(ctx context.Context, instanceName string) string {
+	if name := resolveInstance(ctx, instanceName); name != "" {
+		return name
+	}
+	return pool.GetDefaultInstanceName()
+}
+
+// principalName 返回ctx中调用方的名称，没有认证时为空字符串
+func principalName(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.Name
+	}
+	return ""
+}
+
+// countOf 将Zabbix以字符串返回的数量转换为整数
+func countOf(v interface{}) int {
+	switch n := v.(type) {
+	case string:
+		count, _ := strconv.Atoi(n)
+		return count
+	case float64:
+		return int(n)
+	case []interface{}:
+		return len(n)
+	}
+	return 0
+}
+
+// namesOf 提取对象列表中指定字段的值，例如主机组或模板的名称
+func namesOf(v interface{}, key string) []string {
+	list, _ := v.([]interface{})
+	names := make([]string, 0, len(list))
+	for _, entry := range list {
+		if m, ok := entry.(map[string]interface{}); ok {
+			if name, ok := m[key].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"zabbix-mcp-go/redact"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // GetHostsHandler 获取主机列表
 func GetHostsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetHostsHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetHostsHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -37,18 +38,18 @@ func GetHostsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 
 	GetSugar().Infof("获取主机列表 - 实例: %s, 组ID: %s, 主机名: %s", instanceName, groupID, hostName)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
 	// 获取所有主机，然后手动分页
-	allHosts, err := client.GetHosts(groupID, hostName)
+	allHosts, err := client.GetHostsContext(ctx, groupID, hostName)
 	if err != nil {
 		GetSugar().Errorf("获取主机列表失败: %v", err)
-		return nil, fmt.Errorf("获取主机列表失败: %v", err)
+		return errorResult("获取主机列表失败", err), nil
 	}
 
 	total := len(allHosts)
@@ -84,7 +85,7 @@ func GetHostsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -99,7 +100,7 @@ func GetHostsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 
 // GetHostByNameHandler 根据主机名获取主机信息
 func GetHostByNameHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetHostByNameHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetHostByNameHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -113,28 +114,28 @@ func GetHostByNameHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 	}
 
 	if hostName == "" {
-		return nil, fmt.Errorf("主机名不能为空")
+		return validationError("主机名不能为空"), nil
 	}
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
 	// 取消 detailed 参数，统一使用轻量级查询（已验证两种模式返回一致）
 	GetSugar().Infof("使用轻量级模式获取主机信息: %s", hostName)
-	host, err := client.GetHostByNameLite(hostName)
+	host, err := client.GetHostByNameLiteContext(ctx, hostName)
 
 	if err != nil {
-		return nil, fmt.Errorf("获取主机信息失败: %v", err)
+		return errorResult("获取主机信息失败", err), nil
 	}
 
 	// 将主机信息序列化为 JSON 返回
 	resultData, err := json.Marshal(host)
 	if err != nil {
 		GetSugar().Errorf("JSON 序列化失败: %v", err)
-		return nil, fmt.Errorf("数据格式化失败: %v", err)
+		return internalError("数据格式化失败", err), nil
 	}
 
 	return mcp.NewToolResultText(string(resultData)), nil
@@ -142,7 +143,7 @@ func GetHostByNameHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 
 // CreateHostHandler 创建主机
 func CreateHostHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用CreateHostHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用CreateHostHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -164,18 +165,18 @@ func CreateHostHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	}
 
 	if hostName == "" || groupID == "" || interfaceIP == "" {
-		return nil, fmt.Errorf("主机名、组ID和接口IP不能为空")
+		return validationError("主机名、组ID和接口IP不能为空"), nil
 	}
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	hostID, err := client.CreateHost(hostName, groupID, interfaceIP)
+	hostID, err := client.CreateHostContext(ctx, hostName, groupID, interfaceIP)
 	if err != nil {
-		return nil, fmt.Errorf("创建主机失败: %v", err)
+		return errorResult("创建主机失败", err), nil
 	}
 
 	resultData, _ := json.Marshal(map[string]interface{}{
@@ -187,7 +188,7 @@ func CreateHostHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 
 // DeleteHostHandler 删除主机
 func DeleteHostHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用DeleteHostHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用DeleteHostHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -201,18 +202,41 @@ func DeleteHostHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	}
 
 	if hostID == "" {
-		return nil, fmt.Errorf("主机ID不能为空")
+		return validationError("主机ID不能为空"), nil
 	}
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	err := client.DeleteHost(hostID)
+	// 删除主机前必须先预览并确认
+	preview := confirmChange(ctx, "delete_host", req, true, func() (map[string]interface{}, error) {
+		host, err := client.GetHostDeletePreviewContext(ctx, hostID)
+		if err != nil {
+			return nil, fmt.Errorf("获取主机信息失败: %w", err)
+		}
+		return map[string]interface{}{
+			"action":          "删除主机，以下内容将被一起删除且无法恢复",
+			"hostid":          host["hostid"],
+			"host":            host["host"],
+			"name":            host["name"],
+			"groups":          namesOf(host["groups"], "name"),
+			"templates":       namesOf(host["parentTemplates"], "name"),
+			"items":           countOf(host["items"]),
+			"triggers":        countOf(host["triggers"]),
+			"graphs":          countOf(host["graphs"]),
+			"discovery_rules": countOf(host["discoveries"]),
+		}, nil
+	})
+	if preview != nil {
+		return preview, nil
+	}
+
+	err := client.DeleteHostContext(ctx, hostID)
 	if err != nil {
-		return nil, fmt.Errorf("删除主机失败: %v", err)
+		return errorResult("删除主机失败", err), nil
 	}
 
 	resultData, _ := json.Marshal(map[string]interface{}{
@@ -224,7 +248,7 @@ func DeleteHostHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 
 // GetHostTemplatesHandler 获取主机关联的模板
 func GetHostTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetHostTemplatesHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetHostTemplatesHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -238,22 +262,22 @@ func GetHostTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	}
 
 	if hostID == "" {
-		return nil, fmt.Errorf("主机ID不能为空")
+		return validationError("主机ID不能为空"), nil
 	}
 
 	GetSugar().Infof("获取主机模板 - 实例: %s, 主机ID: %s", instanceName, hostID)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	templates, err := client.GetTemplatesByHost(hostID)
+	templates, err := client.GetTemplatesByHostContext(ctx, hostID)
 	if err != nil {
 		GetSugar().Errorf("获取主机关联模板失败: %v", err)
-		return nil, fmt.Errorf("获取主机关联模板失败: %v", err)
+		return errorResult("获取主机关联模板失败", err), nil
 	}
 
 	GetSugar().Infof("成功获取主机 %s 的模板列表，共 %d 个模板", hostID, len(templates))
@@ -280,7 +304,7 @@ func GetHostTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	resultData, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("JSON序列化失败: %v", err)
-		return nil, fmt.Errorf("数据格式化失败: %v", err)
+		return internalError("数据格式化失败", err), nil
 	}
 
 	return mcp.NewToolResultText(string(resultData)), nil
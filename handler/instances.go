@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"zabbix-mcp-go/redact"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -12,27 +13,22 @@ import (
 func ListInstancesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	GetSugar().Info("调用ListInstancesHandler")
 
-	instances := pool.ListInstances()
+	instances := filterInstances(ctx, pool.ListInstances())
 	GetSugar().Infof("成功获取实例列表，共 %d 个实例", len(instances))
 
-	// 提取实例名称列表
-	var instanceNames []string
-	for _, instance := range instances {
-		if name, ok := instance["name"].(string); ok {
-			instanceNames = append(instanceNames, name)
-		}
-	}
-
+	// 返回实例状态，便于区分已连接、等待重连和不健康的实例
 	resultData, _ := json.Marshal(map[string]interface{}{
-		"instances": instanceNames,
-		"count":     len(instanceNames),
+		"instances": instances,
+		"count":     len(instances),
+		"default":   pool.GetDefaultInstanceName(),
+		"current":   currentInstance(ctx),
 	})
 	return mcp.NewToolResultText(string(resultData)), nil
 }
 
-// SwitchInstanceHandler 切换当前实例
+// SwitchInstanceHandler 切换当前会话使用的实例
 func SwitchInstanceHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用SwitchInstanceHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用SwitchInstanceHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -42,7 +38,7 @@ func SwitchInstanceHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	}
 
 	if instanceName == "" {
-		return nil, fmt.Errorf("实例名称不能为空")
+		return validationError("实例名称不能为空"), nil
 	}
 
 	GetSugar().Infof("切换实例 - 目标实例: %s", instanceName)
@@ -50,17 +46,19 @@ func SwitchInstanceHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	clientRaw := pool.GetClient(instanceName)
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例: %s", instanceName)
+		return instanceNotFound(instanceName), nil
 	}
 	_ = getZabbixClient(clientRaw)
 
-	// 设置默认实例
-	if err := pool.SetDefault(instanceName); err != nil {
-		GetSugar().Errorf("设置默认实例失败: %v", err)
-		return nil, fmt.Errorf("设置默认实例失败: %v", err)
+	// 只记录当前会话的选择，不修改全局默认实例，避免影响共用同一服务的其他会话
+	sessionID := SessionID(ctx)
+	if sessionID == "" {
+		GetSugar().Errorf("切换实例失败: 当前调用没有会话信息")
+		return internalError("切换实例失败", fmt.Errorf("当前调用没有会话信息")), nil
 	}
+	selections.Store(sessionID, instanceName)
 
-	GetSugar().Infof("成功切换到实例: %s", instanceName)
+	GetSugar().Infof("会话 %s 成功切换到实例: %s", sessionID, instanceName)
 
 	resultData, _ := json.Marshal(map[string]interface{}{
 		"current_instance": instanceName,
@@ -71,7 +69,7 @@ func SwitchInstanceHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 
 // GetInstancesInfoHandler 获取实例信息
 func GetInstancesInfoHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetInstancesInfoHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetInstancesInfoHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -82,24 +80,34 @@ func GetInstancesInfoHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 
 	GetSugar().Infof("获取实例信息 - 实例: %s", instanceName)
 
-	clientRaw := pool.GetClient(instanceName)
-	if clientRaw == nil {
-		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例: %s", instanceName)
+	var result map[string]interface{}
+	if instanceName != "" {
+		clientRaw := pool.GetClient(instanceName)
+		if clientRaw == nil {
+			GetSugar().Errorf("未找到指定的实例: %s", instanceName)
+			return instanceNotFound(instanceName), nil
+		}
+		client := getZabbixClient(clientRaw)
+
+		// 包含版本、连接状态和熔断器状态
+		result = client.GetInstanceInfoContext(ctx)
+		result["name"] = instanceName
+	} else {
+		// 未指定实例时返回调用方可以访问的所有实例的信息
+		result = pool.GetAllInstancesInfoContext(ctx)
+		if instances, ok := result["instances"].([]map[string]interface{}); ok {
+			instances = filterInstances(ctx, instances)
+			result["instances"] = instances
+			result["total_count"] = len(instances)
+		}
 	}
-	// 只检查客户端是否存在，不调用具体方法
 
 	GetSugar().Infof("成功获取实例 %s 的信息", instanceName)
 
-	result := map[string]interface{}{
-		"instance": instanceName,
-		"status":   "connected",
-	}
-
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -8,13 +8,16 @@ import (
 	"regexp"
 	"strconv"
 	"time"
+	"zabbix-mcp-go/redact"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GetItemsHandler 获取监控项列表
 func GetItemsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetItemsHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetItemsHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -46,18 +49,18 @@ func GetItemsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	GetSugar().Infof("获取监控项列表 - 实例: %s, 主机ID: %s, 监控项名称: %s, 类型: %s, 页码: %d, 每页数量: %d",
 		instanceName, hostID, itemName, itemType, page, pageSize)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
 	// 获取所有监控项，然后手动分页
-	allItems, err := client.GetItems(hostID, itemName)
+	allItems, err := client.GetItemsContext(ctx, hostID, itemName)
 	if err != nil {
 		GetSugar().Errorf("获取监控项列表失败: %v", err)
-		return nil, fmt.Errorf("获取监控项列表失败: %v", err)
+		return errorResult("获取监控项列表失败", err), nil
 	}
 
 	total := len(allItems)
@@ -93,7 +96,7 @@ func GetItemsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -108,7 +111,7 @@ func GetItemsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 
 // GetItemDataHandler 获取监控项数据
 func GetItemDataHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetItemDataHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetItemDataHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -130,44 +133,39 @@ func GetItemDataHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	}
 
 	if itemID == "" {
-		return nil, fmt.Errorf("监控项ID不能为空")
+		return validationError("监控项ID不能为空"), nil
 	}
 
 	// 解析时间范围
 	timeFrom, timeTill, err := parseTimeRange(timeRange)
 	if err != nil {
 		GetSugar().Errorf("解析时间范围失败: %v", err)
-		return nil, fmt.Errorf("解析时间范围失败: %v", err)
+		return validationError("解析时间范围失败: %v", err), nil
 	}
 
 	GetSugar().Infof("获取监控项数据 - 实例: %s, 监控项ID: %s, 历史数据类型: %d, 时间范围: %s (%s 至 %s)",
 		instanceName, itemID, history, timeRange, timeFrom, timeTill)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	// 获取监控项信息
-	item, err := client.GetItemInfo(itemID)
+	// 通过一次批量请求获取监控项信息和历史数据（使用解析后的时间范围）
+	item, historyData, err := client.GetItemWithHistoryContext(ctx, itemID, history, timeFrom, timeTill)
 	if err != nil {
-		GetSugar().Errorf("获取监控项信息失败: %v", err)
-		return nil, fmt.Errorf("获取监控项信息失败: %v", err)
-	}
-
-	// 获取监控项历史数据（使用解析后的时间范围）
-	historyData, err := client.GetItemDataWithTimeRange(itemID, history, timeFrom, timeTill)
-	if err != nil {
-		GetSugar().Errorf("获取监控项历史数据失败: %v", err)
-		return nil, fmt.Errorf("获取监控项历史数据失败: %v", err)
+		GetSugar().Errorf("获取监控项数据失败: %v", err)
+		return errorResult("获取监控项数据失败", err), nil
 	}
 
 	GetSugar().Infof("成功获取监控项 %s 的数据，共 %d 条历史记录", itemID, len(historyData))
 
 	// 处理历史数据并计算统计信息，同时保留原始数据返回
+	_, span := tracer.Start(ctx, "processHistoryData", trace.WithAttributes(attribute.Int("history.count", len(historyData))))
 	processedHistory, stats := processHistoryData(historyData)
+	span.End()
 	// 应用返回限制（当前返回全部原始历史数据）
 	returnData := historyData
 	// 构建响应结果
@@ -187,7 +185,7 @@ func GetItemDataHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -202,7 +200,7 @@ func GetItemDataHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 
 // CreateItemHandler 创建监控项
 func CreateItemHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用CreateItemHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用CreateItemHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -236,23 +234,23 @@ func CreateItemHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	}
 
 	if hostID == "" || itemName == "" || key == "" {
-		return nil, fmt.Errorf("主机ID、监控项名称和键值不能为空")
+		return validationError("主机ID、监控项名称和键值不能为空"), nil
 	}
 
 	GetSugar().Infof("创建监控项 - 实例: %s, 主机ID: %s, 监控项名称: %s, 键值: %s, 类型: %s, 值类型: %s",
 		instanceName, hostID, itemName, key, itemType, valueType)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	itemID, err := client.CreateItem(hostID, itemName, key, itemType, valueType, delay)
+	itemID, err := client.CreateItemContext(ctx, hostID, itemName, key, itemType, valueType, delay)
 	if err != nil {
 		GetSugar().Errorf("创建监控项失败: %v", err)
-		return nil, fmt.Errorf("创建监控项失败: %v", err)
+		return errorResult("创建监控项失败", err), nil
 	}
 
 	GetSugar().Infof("监控项创建成功，ID: %s", itemID)
@@ -266,17 +264,35 @@ func CreateItemHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 
 // parseTimeRange 解析时间范围字符串，返回开始和结束时间
 func parseTimeRange(timeRange string) (string, string, error) {
+	duration, err := parseRangeDuration(timeRange)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 计算时间范围
+	now := time.Now()
+	startTime := now.Add(-duration)
+
+	// 格式化为 Zabbix API 期望的格式 (YYYY-MM-DD HH:MM:SS)
+	timeFrom := startTime.Format("2006-01-02 15:04:05")
+	timeTill := now.Format("2006-01-02 15:04:05")
+
+	return timeFrom, timeTill, nil
+}
+
+// parseRangeDuration 解析 1w、7d、2h、10m 形式的时间范围，返回对应的时长
+func parseRangeDuration(timeRange string) (time.Duration, error) {
 	// 使用正则表达式解析时间范围格式
 	re := regexp.MustCompile(`^(\d+)([wdhms])$`)
 	matches := re.FindStringSubmatch(timeRange)
 
 	if len(matches) != 3 {
-		return "", "", fmt.Errorf("无效的时间范围格式: %s，支持格式如: 1w, 7d, 3d, 2h, 10m", timeRange)
+		return 0, fmt.Errorf("无效的时间范围格式: %s，支持格式如: 1w, 7d, 3d, 2h, 10m", timeRange)
 	}
 
 	amount, err := strconv.Atoi(matches[1])
 	if err != nil {
-		return "", "", fmt.Errorf("解析时间数量失败: %v", err)
+		return 0, fmt.Errorf("解析时间数量失败: %v", err)
 	}
 
 	unit := matches[2]
@@ -294,18 +310,10 @@ func parseTimeRange(timeRange string) (string, string, error) {
 	case "s":
 		duration = time.Duration(amount) * time.Second
 	default:
-		return "", "", fmt.Errorf("不支持的时间单位: %s", unit)
+		return 0, fmt.Errorf("不支持的时间单位: %s", unit)
 	}
 
-	// 计算时间范围
-	now := time.Now()
-	startTime := now.Add(-duration)
-
-	// 格式化为 Zabbix API 期望的格式 (YYYY-MM-DD HH:MM:SS)
-	timeFrom := startTime.Format("2006-01-02 15:04:05")
-	timeTill := now.Format("2006-01-02 15:04:05")
-
-	return timeFrom, timeTill, nil
+	return duration, nil
 }
 
 // calculateHistoryStats 计算历史数据的统计信息
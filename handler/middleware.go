@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+	"zabbix-mcp-go/audit"
+	"zabbix-mcp-go/metrics"
+	"zabbix-mcp-go/redact"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer 未配置链路追踪时为空实现
+var tracer = otel.Tracer("zabbix-mcp-go/handler")
+
+// addTool 注册工具，处理函数统一经过 authorize 和 instrument 包装。
+// 工具访问策略禁止的工具不注册，客户端看不到这些工具
+func addTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if !currentToolPolicy().visible(tool.Name) {
+		GetSugar().Infof("工具 %s 被工具访问策略禁用，不注册", tool.Name)
+		return
+	}
+	s.AddTool(tool, instrument(tool.Name, authorize(tool.Name, handler)))
+}
+
+// instrument 包装工具处理函数：每次调用作为一个根span，记录调用次数、结果和耗时，并为审计日志记录调用方。
+// 处理函数内的Zabbix API调用通过ctx成为该span的子span。
+func instrument(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		attrs := []attribute.KeyValue{attribute.String("mcp.tool", name)}
+		if instance, ok := req.Params.Arguments["instance"].(string); ok && instance != "" {
+			attrs = append(attrs, attribute.String("zabbix.instance", instance))
+		}
+		ctx, span := tracer.Start(ctx, "tools/call "+name,
+			trace.WithNewRoot(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		// 处理函数发起的Zabbix写操作记录到审计日志时带上调用方和工具参数
+		ctx = audit.WithCaller(ctx, audit.Caller{
+			Session:   SessionID(ctx),
+			Client:    principalName(ctx),
+			Tool:      name,
+			Arguments: redact.Args(req.Params.Arguments),
+		})
+
+		start := time.Now()
+		result, err := handler(ctx, req)
+		success := err == nil && (result == nil || !result.IsError)
+		metrics.ObserveToolCall(name, success, time.Since(start))
+
+		if !success {
+			span.SetStatus(codes.Error, "工具调用失败")
+			if err != nil {
+				span.RecordError(errors.New(redact.String(err.Error())))
+			} else if len(result.Content) > 0 {
+				// 处理函数通过 IsError 结果返回错误，内容已经屏蔽过敏感信息
+				if text, ok := result.Content[0].(mcp.TextContent); ok {
+					span.RecordError(errors.New(text.Text))
+				}
+			}
+		}
+		return result, err
+	}
+}
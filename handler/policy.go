@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"sync"
+)
+
+// ToolPolicy 工具访问策略，由主程序根据配置设置
+type ToolPolicy struct {
+	// ReadOnly 只允许只读工具
+	ReadOnly bool
+	// Instances 每个已配置实例的工具白名单和黑名单，没有限制的实例对应空策略
+	Instances map[string]InstanceToolPolicy
+}
+
+// InstanceToolPolicy 实例的工具白名单和黑名单。Allow 非空时只允许其中的工具，Deny 中的工具总是禁止
+type InstanceToolPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+var (
+	toolPolicy   ToolPolicy
+	toolPolicyMu sync.RWMutex
+)
+
+// SetToolPolicy 设置工具访问策略。注册工具前调用决定哪些工具对客户端可见，
+// 之后调用（例如配置热加载）只影响调用时的检查
+func SetToolPolicy(policy ToolPolicy) {
+	toolPolicyMu.Lock()
+	defer toolPolicyMu.Unlock()
+	toolPolicy = policy
+}
+
+// currentToolPolicy 获取当前工具访问策略
+func currentToolPolicy() ToolPolicy {
+	toolPolicyMu.RLock()
+	defer toolPolicyMu.RUnlock()
+	return toolPolicy
+}
+
+// IsKnownTool 判断是否为已注册的工具名称，用于校验配置
+func IsKnownTool(name string) bool {
+	_, exists := toolAccessRules[name]
+	return exists
+}
+
+// allows 判断实例是否允许调用工具
+func (p ToolPolicy) allows(tool, instanceName string) bool {
+	if p.ReadOnly && !toolAccessRules[tool].readOnly {
+		return false
+	}
+	instancePolicy := p.Instances[instanceName]
+	if len(instancePolicy.Allow) > 0 && !containsString(instancePolicy.Allow, tool) {
+		return false
+	}
+	return !containsString(instancePolicy.Deny, tool)
+}
+
+// visible 判断工具是否需要注册：只读模式下不注册修改数据的工具，所有实例都禁止的工具也不注册
+func (p ToolPolicy) visible(tool string) bool {
+	if p.ReadOnly && !toolAccessRules[tool].readOnly {
+		return false
+	}
+	if len(p.Instances) == 0 {
+		return true
+	}
+	for instanceName := range p.Instances {
+		if p.allows(tool, instanceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString 判断切片中是否包含字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
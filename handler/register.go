@@ -8,7 +8,7 @@ import (
 // RegisterTools 注册所有工具处理函数
 func RegisterTools(s *server.MCPServer) {
 	// 主机相关工具
-	s.AddTool(
+	addTool(s,
 		// 获取主机列表
 		mcp.NewTool("get_hosts",
 			mcp.WithDescription("获取Zabbix主机列表，支持分页查询"),
@@ -21,7 +21,7 @@ func RegisterTools(s *server.MCPServer) {
 		GetHostsHandler,
 	)
 	// 主机名获取主机信息
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_host_by_name",
 			mcp.WithDescription("根据主机名获取主机信息"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -30,7 +30,7 @@ func RegisterTools(s *server.MCPServer) {
 		GetHostByNameHandler,
 	)
 	// TODO 创建主机 测试
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("create_host",
 			mcp.WithDescription("创建Zabbix主机"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -41,17 +41,18 @@ func RegisterTools(s *server.MCPServer) {
 		CreateHostHandler,
 	)
 	// TODO 删除主机 测试
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("delete_host",
-			mcp.WithDescription("删除Zabbix主机"),
+			mcp.WithDescription("删除Zabbix主机及其所有监控项、触发器和图形。需要确认：先调用返回将被删除的内容和confirm_token，确认后带上confirm_token再次调用才会执行"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
 			mcp.WithString("host_id", mcp.Required(), mcp.Description("主机ID")),
+			withConfirmation(),
 		),
 		DeleteHostHandler,
 	)
 
 	// 监控项相关工具
-	s.AddTool(
+	addTool(s,
 		// 获取主机监控项 完成
 		mcp.NewTool("get_host_items",
 			mcp.WithDescription("获取主机监控项，支持监控项名称模糊匹配"),
@@ -62,7 +63,7 @@ func RegisterTools(s *server.MCPServer) {
 		GetItemsHandler,
 	)
 	// 获取主机监控项数据支持时间范围
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_item_data",
 			mcp.WithDescription("获取监控项数据，通过监控项ID获取数据"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -73,7 +74,7 @@ func RegisterTools(s *server.MCPServer) {
 		GetItemDataHandler,
 	)
 	// TODO 创建监控项 测试
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("create_item",
 			mcp.WithDescription("创建监控项"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -88,7 +89,7 @@ func RegisterTools(s *server.MCPServer) {
 	)
 
 	// TODO 触发器相关工具  测试
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_triggers",
 			mcp.WithDescription("获取触发器列表"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -97,7 +98,7 @@ func RegisterTools(s *server.MCPServer) {
 		),
 		GetTriggersHandler,
 	)
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_trigger_events",
 			mcp.WithDescription("获取触发器事件"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -106,18 +107,19 @@ func RegisterTools(s *server.MCPServer) {
 		),
 		GetTriggerEventsHandler,
 	)
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("acknowledge_event",
-			mcp.WithDescription("确认事件"),
+			mcp.WithDescription("确认事件。一次确认多个事件时需要确认：先调用返回将被确认的事件和confirm_token，确认后带上confirm_token再次调用才会执行"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
-			mcp.WithString("event_id", mcp.Required(), mcp.Description("事件ID")),
+			withStringArray("event_ids", mcp.Required(), mcp.Description("事件ID列表")),
 			mcp.WithString("message", mcp.Description("确认消息")),
+			withConfirmation(),
 		),
 		AcknowledgeEventHandler,
 	)
 
 	// 模板相关工具
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_templates",
 			mcp.WithDescription("获取模板列表"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -125,7 +127,7 @@ func RegisterTools(s *server.MCPServer) {
 		GetTemplatesHandler,
 	)
 	// info 获取模板信息 完成
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_host_templates",
 			mcp.WithDescription("获取指定主机的模板信息"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
@@ -133,45 +135,91 @@ func RegisterTools(s *server.MCPServer) {
 		),
 		GetHostTemplatesHandler,
 	)
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("link_template",
 			mcp.WithDescription("关联模板到主机"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
 			mcp.WithString("host_id", mcp.Required(), mcp.Description("主机ID")),
-			mcp.WithString("template_id", mcp.Required(), mcp.Description("模板ID")),
+			withStringArray("template_ids", mcp.Required(), mcp.Description("模板ID列表")),
 		),
 		LinkTemplateHandler,
 	)
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("unlink_template",
-			mcp.WithDescription("从主机移除模板"),
+			mcp.WithDescription("从主机移除模板。clear为true时会删除从模板继承的监控项和触发器，需要确认：先调用返回将被删除的内容和confirm_token，确认后带上confirm_token再次调用才会执行"),
 			mcp.WithString("instance", mcp.Description("Zabbix实例名称")),
 			mcp.WithString("host_id", mcp.Required(), mcp.Description("主机ID")),
-			mcp.WithString("template_id", mcp.Required(), mcp.Description("模板ID")),
+			withStringArray("template_ids", mcp.Required(), mcp.Description("模板ID列表")),
+			mcp.WithBoolean("clear", mcp.Description("同时删除从模板继承的监控项和触发器，默认只取消关联")),
+			withConfirmation(),
 		),
 		UnlinkTemplateHandler,
 	)
 
 	// info 多实例管理 完成
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("list_instances",
-			mcp.WithDescription("列出所有Zabbix实例"),
+			mcp.WithDescription("列出所有Zabbix实例，以及全局默认实例和当前会话使用的实例"),
 		),
 		ListInstancesHandler,
 	)
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("switch_instance",
-			mcp.WithDescription("切换当前使用的实例"),
+			mcp.WithDescription("切换当前会话使用的实例，不影响其他会话"),
 			mcp.WithString("instance", mcp.Required(), mcp.Description("实例名称")),
 		),
 		SwitchInstanceHandler,
 	)
 
 	// info 实例信息工具 完成
-	s.AddTool(
+	addTool(s,
 		mcp.NewTool("get_instances_info",
-			mcp.WithDescription("获取所有Zabbix实例的详细信息"),
+			mcp.WithDescription("获取所有Zabbix实例的详细信息，包括版本、连接状态和熔断器状态"),
+			mcp.WithString("instance", mcp.Description("Zabbix实例名称，不传入则返回所有实例")),
 		),
 		GetInstancesInfoHandler,
 	)
+
+	// 审计日志
+	addTool(s,
+		mcp.NewTool("get_audit_log",
+			mcp.WithDescription("查询通过本服务执行的Zabbix写操作审计日志，按时间从新到旧返回"),
+			mcp.WithString("instance", mcp.Description("只返回该实例的记录，不传入则返回所有可访问实例的记录")),
+			mcp.WithString("session", mcp.Description("MCP会话ID")),
+			mcp.WithString("client", mcp.Description("HTTP认证的客户端名称")),
+			mcp.WithString("tool", mcp.Description("工具名称，如 delete_host")),
+			mcp.WithString("method", mcp.Description("Zabbix API方法，如 host.delete")),
+			mcp.WithString("object_id", mcp.Description("受影响的对象ID，如主机ID")),
+			mcp.WithString("time_range", mcp.Description("只返回最近这段时间的记录，支持格式：1w(1周), 7d(7天), 2h(2小时), 10m(10分钟)")),
+			mcp.WithBoolean("failed_only", mcp.Description("只返回失败的调用")),
+			mcp.WithNumber("limit", mcp.DefaultNumber(50), mcp.Description("最多返回的记录数，默认50，最大500")),
+		),
+		GetAuditLogHandler,
+	)
+}
+
+// withConfirmation 为破坏性工具添加 dry_run 和 confirm_token 参数
+func withConfirmation() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithBoolean("dry_run", mcp.Description("只预览将要发生的修改，不执行"))(t)
+		mcp.WithString("confirm_token", mcp.Description("预览时返回的确认令牌，带上后才会执行修改"))(t)
+	}
+}
+
+// withStringArray 添加字符串数组参数，mcp-go v0.9.0 没有提供数组类型的参数选项
+func withStringArray(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		schema := map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}
+		for _, opt := range opts {
+			opt(schema)
+		}
+		if required, ok := schema["required"].(bool); ok && required {
+			delete(schema, "required")
+			t.InputSchema.Required = append(t.InputSchema.Required, name)
+		}
+		t.InputSchema.Properties[name] = schema
+	}
 }
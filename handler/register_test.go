@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolSchema tools/list 返回的工具参数定义中用到的字段
+type toolSchema struct {
+	Name        string
+	InputSchema struct {
+		Properties map[string]map[string]interface{}
+		Required   []string
+	}
+}
+
+// callMCP 通过MCP协议发送请求，返回响应中的 result
+func callMCP(t *testing.T, s *server.MCPServer, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	message, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	data, _ := json.Marshal(s.HandleMessage(context.Background(), message))
+	var response struct {
+		Result json.RawMessage
+		Error  interface{}
+	}
+	if err := json.Unmarshal(data, &response); err != nil || response.Error != nil {
+		t.Fatalf("%s 调用失败: %s", method, data)
+	}
+	return response.Result
+}
+
+// TestToolArgumentsMatchSchema 按 tools/list 公布的参数名调用工具，确认处理函数读取的参数与schema一致
+func TestToolArgumentsMatchSchema(t *testing.T) {
+	fake := newFakeZabbix(t, map[string]interface{}{
+		"template.get": []interface{}{
+			map[string]interface{}{"templateid": "10001", "name": "Linux by Zabbix agent", "items": "40", "triggers": "12"},
+			map[string]interface{}{"templateid": "10047", "name": "ICMP Ping", "items": "3", "triggers": "2"},
+		},
+		"event.get": []interface{}{
+			map[string]interface{}{"eventid": "501", "name": "High CPU"},
+			map[string]interface{}{"eventid": "502", "name": "Disk full"},
+		},
+		"template.massadd": map[string]interface{}{"templateids": []interface{}{"10001", "10047"}},
+	})
+
+	s := server.NewMCPServer("test", "1.0.0")
+	RegisterTools(s)
+
+	var list struct{ Tools []toolSchema }
+	if err := json.Unmarshal(callMCP(t, s, "tools/list", map[string]interface{}{}), &list); err != nil {
+		t.Fatal(err)
+	}
+	schemas := make(map[string]toolSchema)
+	for _, tool := range list.Tools {
+		schemas[tool.Name] = tool
+	}
+
+	// 按schema中的类型给出参数值
+	values := map[string]interface{}{
+		"host_id":      "10084",
+		"template_ids": []interface{}{"10001", "10047"},
+		"event_ids":    []interface{}{"501", "502"},
+		"clear":        true,
+		"message":      "已处理",
+	}
+	argumentsFor := func(tool toolSchema) map[string]interface{} {
+		args := make(map[string]interface{})
+		for name, property := range tool.InputSchema.Properties {
+			value, ok := values[name]
+			if !ok {
+				continue
+			}
+			if _, isArray := value.([]interface{}); isArray != (property["type"] == "array") {
+				t.Fatalf("%s 的参数 %s 类型为 %v", tool.Name, name, property["type"])
+			}
+			args[name] = value
+		}
+		for _, name := range tool.InputSchema.Required {
+			if _, ok := args[name]; !ok {
+				t.Fatalf("%s 的必填参数 %s 没有测试值", tool.Name, name)
+			}
+		}
+		return args
+	}
+
+	tests := []struct {
+		tool string
+		want string // 结果中应包含的内容
+	}{
+		{"unlink_template", "confirm_token"},
+		{"acknowledge_event", "confirm_token"},
+		{"link_template", "成功关联 2 个模板到主机"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			schema, ok := schemas[tt.tool]
+			if !ok {
+				t.Fatalf("工具 %s 未注册", tt.tool)
+			}
+			result := callMCP(t, s, "tools/call", map[string]interface{}{
+				"name":      tt.tool,
+				"arguments": argumentsFor(schema),
+			})
+			var toolResult struct {
+				IsError bool
+				Content []struct{ Text string }
+			}
+			if err := json.Unmarshal(result, &toolResult); err != nil {
+				t.Fatal(err)
+			}
+			if toolResult.IsError || len(toolResult.Content) == 0 || !strings.Contains(toolResult.Content[0].Text, tt.want) {
+				t.Errorf("%s 返回 %s，期望包含 %q", tt.tool, result, tt.want)
+			}
+		})
+	}
+
+	if calls := fake.calls("template.massadd"); len(calls) != 1 || len(calls[0]["templates"].([]interface{})) != 2 {
+		t.Errorf("link_template 应以两个模板调用一次 template.massadd，实际: %v", calls)
+	}
+}
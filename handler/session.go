@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"sync"
+)
+
+// StdioSessionID stdio传输只有一个客户端，使用固定的会话ID
+const StdioSessionID = "stdio"
+
+// sessionKey ctx中保存MCP会话ID的键
+type sessionKey struct{}
+
+// selections 各会话通过 switch_instance 选择的实例，sessionID -> 实例名称
+var selections sync.Map
+
+// WithSession 将会话ID保存到ctx，由传输层在处理每条消息前调用。
+// mcp-go v0.9.0 没有把会话信息传给工具处理函数，因此需要传输层自己注入。
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// SessionID 获取ctx中的会话ID，没有时返回空字符串
+func SessionID(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionKey{}).(string)
+	return sessionID
+}
+
+// EndSession 会话结束时清理该会话选择的实例和未使用的确认令牌
+func EndSession(sessionID string) {
+	selections.Delete(sessionID)
+	dropConfirmTokens(sessionID)
+}
+
+// selectedInstance 获取当前会话选择的实例，没有选择时返回空字符串
+func selectedInstance(ctx context.Context) string {
+	sessionID := SessionID(ctx)
+	if sessionID == "" {
+		return ""
+	}
+	if name, ok := selections.Load(sessionID); ok {
+		return name.(string)
+	}
+	return ""
+}
+
+// resolveInstance 确定本次调用使用的实例：优先使用参数指定的实例，其次是当前会话选择的实例，
+// 都没有时返回空字符串，由连接池使用全局默认实例。
+// 会话选择的实例已被移除（例如配置热加载）时同样回退到默认实例。
+func resolveInstance(ctx context.Context, instanceName string) string {
+	if instanceName != "" {
+		return instanceName
+	}
+	selected := selectedInstance(ctx)
+	if selected != "" && pool.GetClient(selected) != nil {
+		return selected
+	}
+	return ""
+}
+
+// currentInstance 返回当前会话实际使用的实例名称
+func currentInstance(ctx context.Context) string {
+	if name := resolveInstance(ctx, ""); name != "" {
+		return name
+	}
+	return pool.GetDefaultInstanceName()
+}
@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"zabbix-mcp-go/redact"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // GetTemplatesHandler 获取模板列表
 func GetTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetTemplatesHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetTemplatesHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -35,18 +36,18 @@ func GetTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	GetSugar().Infof("获取模板列表 - 实例: %s, 模板名称: %s, 页码: %d, 每页数量: %d",
 		instanceName, templateName, page, pageSize)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
 	// 获取所有模板，然后手动分页和过滤
-	allTemplates, err := client.GetTemplates()
+	allTemplates, err := client.GetTemplatesContext(ctx)
 	if err != nil {
 		GetSugar().Errorf("获取模板列表失败: %v", err)
-		return nil, fmt.Errorf("获取模板列表失败: %v", err)
+		return errorResult("获取模板列表失败", err), nil
 	}
 
 	// 根据模板名称过滤
@@ -92,7 +93,7 @@ func GetTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -107,7 +108,7 @@ func GetTemplatesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 
 // LinkTemplateHandler 关联模板到主机
 func LinkTemplateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用LinkTemplateHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用LinkTemplateHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -129,22 +130,22 @@ func LinkTemplateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	}
 
 	if hostID == "" || len(templateIDs) == 0 {
-		return nil, fmt.Errorf("主机ID和模板ID列表不能为空")
+		return validationError("主机ID和模板ID列表不能为空"), nil
 	}
 
 	GetSugar().Infof("关联模板 - 实例: %s, 主机ID: %s, 模板ID: %v", instanceName, hostID, templateIDs)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	err := client.LinkTemplates(hostID, templateIDs)
+	err := client.LinkTemplatesContext(ctx, hostID, templateIDs)
 	if err != nil {
 		GetSugar().Errorf("关联模板失败: %v", err)
-		return nil, fmt.Errorf("关联模板失败: %v", err)
+		return errorResult("关联模板失败", err), nil
 	}
 
 	GetSugar().Infof("成功关联 %d 个模板到主机 %s", len(templateIDs), hostID)
@@ -159,7 +160,7 @@ func LinkTemplateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 
 // UnlinkTemplateHandler 从主机取消关联模板
 func UnlinkTemplateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用UnlinkTemplateHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用UnlinkTemplateHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -185,22 +186,58 @@ func UnlinkTemplateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	}
 
 	if hostID == "" || len(templateIDs) == 0 {
-		return nil, fmt.Errorf("主机ID和模板ID列表不能为空")
+		return validationError("主机ID和模板ID列表不能为空"), nil
 	}
 
 	GetSugar().Infof("取消关联模板 - 实例: %s, 主机ID: %s, 模板ID: %v, 清除实例: %t", instanceName, hostID, templateIDs, clear)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	err := client.UnlinkTemplates(hostID, templateIDs, clear)
+	// 清除模板会删除从模板继承的监控项和触发器，必须先预览并确认
+	preview := confirmChange(ctx, "unlink_template", req, clear, func() (map[string]interface{}, error) {
+		templates, err := client.GetTemplateUnlinkPreviewContext(ctx, hostID, templateIDs)
+		if err != nil {
+			return nil, fmt.Errorf("获取模板信息失败: %w", err)
+		}
+		items, triggers := 0, 0
+		templateList := make([]map[string]interface{}, 0, len(templates))
+		for _, template := range templates {
+			items += countOf(template["items"])
+			triggers += countOf(template["triggers"])
+			templateList = append(templateList, map[string]interface{}{
+				"templateid": template["templateid"],
+				"name":       template["name"],
+				"items":      countOf(template["items"]),
+				"triggers":   countOf(template["triggers"]),
+			})
+		}
+		changes := map[string]interface{}{
+			"hostid":    hostID,
+			"clear":     clear,
+			"templates": templateList,
+		}
+		if clear {
+			changes["action"] = "取消关联并清除模板，以下监控项和触发器将从主机上删除，历史数据无法恢复"
+			changes["items_removed"] = items
+			changes["triggers_removed"] = triggers
+		} else {
+			changes["action"] = "取消关联模板，从模板继承的监控项和触发器保留在主机上"
+		}
+		return changes, nil
+	})
+	if preview != nil {
+		return preview, nil
+	}
+
+	err := client.UnlinkTemplatesContext(ctx, hostID, templateIDs, clear)
 	if err != nil {
 		GetSugar().Errorf("取消关联模板失败: %v", err)
-		return nil, fmt.Errorf("取消关联模板失败: %v", err)
+		return errorResult("取消关联模板失败", err), nil
 	}
 
 	GetSugar().Infof("成功从主机 %s 取消关联 %d 个模板", hostID, len(templateIDs))
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestUnlinkTemplateKeepsInheritedEntities clear 为 false 时只取消关联，不能删除继承的监控项和触发器
+func TestUnlinkTemplateKeepsInheritedEntities(t *testing.T) {
+	fake := newFakeZabbix(t, map[string]interface{}{
+		"template.get": []interface{}{
+			map[string]interface{}{"templateid": "10001", "name": "Linux by Zabbix agent", "items": "40", "triggers": "12"},
+		},
+		"host.massremove": map[string]interface{}{"hostids": []interface{}{"10084"}},
+	})
+
+	call := func(args map[string]interface{}) string {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = args
+		result, err := UnlinkTemplateHandler(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("unlink_template 调用失败: %v %+v", err, result)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	preview := call(map[string]interface{}{"host_id": "10084", "template_ids": []interface{}{"10001"}, "dry_run": true})
+	if !strings.Contains(preview, "保留在主机上") {
+		t.Errorf("预览应说明继承的监控项和触发器会保留: %s", preview)
+	}
+	if calls := fake.calls("host.massremove"); len(calls) != 0 {
+		t.Fatalf("dry_run 不应执行修改: %v", calls)
+	}
+
+	call(map[string]interface{}{"host_id": "10084", "template_ids": []interface{}{"10001"}})
+	calls := fake.calls("host.massremove")
+	if len(calls) != 1 {
+		t.Fatalf("应调用一次 host.massremove，实际: %v", calls)
+	}
+	if _, ok := calls[0]["templateids"]; !ok {
+		t.Errorf("应使用 templateids 只取消关联: %v", calls[0])
+	}
+	if _, ok := calls[0]["templateids_clear"]; ok {
+		t.Errorf("clear 为 false 时不能使用 templateids_clear: %v", calls[0])
+	}
+	if calls := fake.calls("host.update"); len(calls) != 0 {
+		t.Errorf("不应通过 host.update 的 templates_clear 取消关联: %v", calls)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// exportedSpan stdouttrace输出的span中用到的字段
+type exportedSpan struct {
+	Name        string
+	SpanContext struct{ SpanID string }
+	Parent      struct{ SpanID string }
+}
+
+func TestGetItemDataTracing(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	newFakeZabbix(t, map[string]interface{}{
+		"item.get": []interface{}{map[string]interface{}{"itemid": "10", "name": "CPU", "value_type": "0"}},
+		"history.get": []interface{}{
+			map[string]interface{}{"itemid": "10", "clock": "1700000000", "value": "1.5"},
+			map[string]interface{}{"itemid": "10", "clock": "1700000060", "value": "2.5"},
+		},
+	})
+	buf.Reset()
+
+	var req mcp.CallToolRequest
+	req.Params.Arguments = map[string]interface{}{"item_id": "10", "time_range": "1h"}
+	result, err := instrument("get_item_data", GetItemDataHandler)(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("get_item_data 调用失败: %v %+v", err, result)
+	}
+
+	spans := make(map[string]exportedSpan)
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var span exportedSpan
+		if err := decoder.Decode(&span); err != nil {
+			t.Fatal(err)
+		}
+		spans[span.Name] = span
+	}
+
+	parentOf := map[string]string{
+		"zabbix batch":       "tools/call get_item_data",
+		"zabbix item.get":    "zabbix batch",
+		"zabbix history.get": "zabbix batch",
+		"processHistoryData": "tools/call get_item_data",
+	}
+	for name, parent := range parentOf {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("缺少span %q，已导出: %v", name, spans)
+			continue
+		}
+		if span.Parent.SpanID != spans[parent].SpanContext.SpanID {
+			t.Errorf("span %q 的父span应为 %q", name, parent)
+		}
+	}
+}
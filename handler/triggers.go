@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"zabbix-mcp-go/redact"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // GetTriggersHandler 获取触发器列表
 func GetTriggersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetTriggersHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetTriggersHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -43,18 +44,18 @@ func GetTriggersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	GetSugar().Infof("获取触发器列表 - 实例: %s, 主机ID: %s, 触发器名称: %s, 仅活跃: %t, 页码: %d, 每页数量: %d",
 		instanceName, hostID, triggerName, activeOnly, page, pageSize)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
 	// 获取所有触发器，然后手动分页
-	allTriggers, err := client.GetTriggers(hostID, activeOnly)
+	allTriggers, err := client.GetTriggersContext(ctx, hostID, activeOnly)
 	if err != nil {
 		GetSugar().Errorf("获取触发器列表失败: %v", err)
-		return nil, fmt.Errorf("获取触发器列表失败: %v", err)
+		return errorResult("获取触发器列表失败", err), nil
 	}
 
 	// 根据触发器名称过滤
@@ -100,7 +101,7 @@ func GetTriggersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -115,7 +116,7 @@ func GetTriggersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 
 // GetTriggerEventsHandler 获取触发器事件
 func GetTriggerEventsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用GetTriggerEventsHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用GetTriggerEventsHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -133,22 +134,22 @@ func GetTriggerEventsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	}
 
 	if triggerID == "" {
-		return nil, fmt.Errorf("触发器ID不能为空")
+		return validationError("触发器ID不能为空"), nil
 	}
 
 	GetSugar().Infof("获取触发器事件 - 实例: %s, 触发器ID: %s, 限制: %d", instanceName, triggerID, limit)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	events, err := client.GetTriggerEvents(triggerID, limit)
+	events, err := client.GetTriggerEventsContext(ctx, triggerID, limit)
 	if err != nil {
 		GetSugar().Errorf("获取触发器事件失败: %v", err)
-		return nil, fmt.Errorf("获取触发器事件失败: %v", err)
+		return errorResult("获取触发器事件失败", err), nil
 	}
 
 	GetSugar().Infof("成功获取触发器 %s 的事件，共 %d 条记录", triggerID, len(events))
@@ -163,7 +164,7 @@ func GetTriggerEventsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		GetSugar().Errorf("序列化结果失败: %v", err)
-		return nil, fmt.Errorf("序列化结果失败: %v", err)
+		return internalError("序列化结果失败", err), nil
 	}
 
 	return &mcp.CallToolResult{
@@ -178,7 +179,7 @@ func GetTriggerEventsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 
 // AcknowledgeEventHandler 确认事件
 func AcknowledgeEventHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	GetSugar().Infof("调用AcknowledgeEventHandler，参数: %+v", req.Params.Arguments)
+	GetSugar().Infof("调用AcknowledgeEventHandler，参数: %+v", redact.Args(req.Params.Arguments))
 
 	args := req.Params.Arguments
 	instanceName := ""
@@ -200,22 +201,49 @@ func AcknowledgeEventHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	}
 
 	if len(eventIDs) == 0 {
-		return nil, fmt.Errorf("事件ID列表不能为空")
+		return validationError("事件ID列表不能为空"), nil
 	}
 
 	GetSugar().Infof("确认事件 - 实例: %s, 事件ID: %v, 消息: %s", instanceName, eventIDs, message)
 
-	clientRaw := pool.GetClient(instanceName)
+	clientRaw := pool.GetClient(resolveInstance(ctx, instanceName))
 	if clientRaw == nil {
 		GetSugar().Errorf("未找到指定的实例: %s", instanceName)
-		return nil, fmt.Errorf("未找到指定的实例")
+		return instanceNotFound(instanceName), nil
 	}
 	client := getZabbixClient(clientRaw)
 
-	err := client.MassAcknowledgeEvents(eventIDs, message)
+	// 批量确认多个事件时必须先预览并确认
+	preview := confirmChange(ctx, "acknowledge_event", req, len(eventIDs) > 1, func() (map[string]interface{}, error) {
+		events, err := client.GetEventsContext(ctx, map[string]interface{}{
+			"output":   []string{"eventid", "name", "severity", "acknowledged", "clock"},
+			"eventids": eventIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取事件信息失败: %w", err)
+		}
+		if len(events) != len(eventIDs) {
+			return nil, &toolError{
+				Category: ErrorNotFound,
+				Message:  fmt.Sprintf("部分事件不存在: 请求 %d 个，找到 %d 个", len(eventIDs), len(events)),
+				Hint:     "确认事件ID是否正确，可以先用 get_trigger_events 查询",
+			}
+		}
+		return map[string]interface{}{
+			"action":  "确认事件",
+			"message": message,
+			"events":  events,
+			"count":   len(events),
+		}, nil
+	})
+	if preview != nil {
+		return preview, nil
+	}
+
+	err := client.MassAcknowledgeEventsContext(ctx, eventIDs, message)
 	if err != nil {
 		GetSugar().Errorf("确认事件失败: %v", err)
-		return nil, fmt.Errorf("确认事件失败: %v", err)
+		return errorResult("确认事件失败", err), nil
 	}
 
 	GetSugar().Infof("成功确认 %d 个事件", len(eventIDs))
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"zabbix-mcp-go/zabbix"
+
+	"go.uber.org/zap"
+)
+
+// fakeZabbix 模拟的Zabbix API，按方法名返回固定结果，并记录收到的请求
+type fakeZabbix struct {
+	*httptest.Server
+	results map[string]interface{}
+
+	mu       sync.Mutex
+	requests []map[string]interface{}
+}
+
+// newFakeZabbix 启动模拟的Zabbix API，并作为唯一实例 test 设置为handler包的连接池
+func newFakeZabbix(t *testing.T, results map[string]interface{}) *fakeZabbix {
+	f := &fakeZabbix{results: map[string]interface{}{"apiinfo.version": "7.0.0"}}
+	for method, result := range results {
+		f.results[method] = result
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+
+	client := zabbix.NewZabbixClient(f.URL, "", "")
+	client.SetAuthToken("test-token")
+	zabbixPool := zabbix.NewZabbixPool()
+	if err := zabbixPool.AddInstance("test", client); err != nil {
+		t.Fatal(err)
+	}
+	SetDependencies(zabbixPool, zap.NewNop().Sugar(), nil, func(c interface{}) ZabbixClient {
+		return c.(*zabbix.ZabbixClient)
+	})
+	return f
+}
+
+// serve 处理单个请求和批量请求
+func (f *fakeZabbix) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var batch []map[string]interface{}
+	if json.Unmarshal(body, &batch) == nil {
+		responses := make([]interface{}, len(batch))
+		for i, req := range batch {
+			responses[i] = f.respond(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(f.respond(req))
+}
+
+func (f *fakeZabbix) respond(req map[string]interface{}) map[string]interface{} {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	return map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": f.results[req["method"].(string)]}
+}
+
+// calls 返回指定方法收到的请求参数
+func (f *fakeZabbix) calls(method string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var params []map[string]interface{}
+	for _, req := range f.requests {
+		if req["method"] == method {
+			p, _ := req["params"].(map[string]interface{})
+			params = append(params, p)
+		}
+	}
+	return params
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"zabbix-mcp-go/zabbix"
+)
+
+// handleHealthz 存活探针：进程能响应HTTP请求即返回200
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+// handleReadyz 就绪探针：读取后台健康检查的缓存结果，不发起任何Zabbix请求。
+// 配置了 required 的实例必须全部健康；没有配置时至少一个实例健康即就绪。
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	health := pool.CachedHealth()
+	ready, reason := readiness(CurrentConfig(), health)
+
+	status := http.StatusOK
+	body := map[string]interface{}{
+		"status":    "ready",
+		"instances": health,
+	}
+	if !ready {
+		status = http.StatusServiceUnavailable
+		body["status"] = "not_ready"
+		body["reason"] = reason
+	}
+	writeJSON(w, status, body)
+}
+
+// readiness 根据配置和健康检查结果判断是否就绪，未就绪时返回原因
+func readiness(config Config, health map[string]zabbix.HealthStatus) (bool, string) {
+	var required []string
+	for _, instance := range config.Instances {
+		if instance.Required {
+			required = append(required, instance.Name)
+		}
+	}
+
+	if len(required) > 0 {
+		for _, name := range required {
+			status, checked := health[name]
+			if !checked {
+				return false, "必需实例 " + name + " 尚未完成健康检查或未连接"
+			}
+			if !status.Healthy {
+				return false, "必需实例 " + name + " 不健康: " + status.Error
+			}
+		}
+		return true, ""
+	}
+
+	for _, status := range health {
+		if status.Healthy {
+			return true, ""
+		}
+	}
+	return false, "没有健康的实例"
+}
+
+// writeJSON 以JSON格式写入HTTP响应
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
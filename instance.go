@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"zabbix-mcp-go/zabbix"
+)
+
+// connectInstance 创建客户端并登录后加入连接池；登录失败的实例以 pending 状态加入，由后台健康检查重连
+func connectInstance(instance ZabbixInstance) error {
+	client, err := newZabbixClient(instance)
+	if err != nil {
+		GetSugar().Errorf("实例 %s 配置错误，跳过该实例: %v", instance.Name, err)
+		return err
+	}
+
+	// 获取实例信息
+	instanceInfo := fmt.Sprintf("实例名称: %s, 地址: %s, 认证方式: %s",
+		instance.Name, instance.URL, instance.AuthType)
+
+	// 检测Zabbix版本（结果缓存在客户端中，后续调用直接复用）
+	version, err := client.GetVersion()
+	if err != nil {
+		GetSugar().Warnf("%s, 状态: 版本检测失败 - %v", instanceInfo, err)
+	} else {
+		instanceInfo = fmt.Sprintf("%s, Zabbix版本: %s", instanceInfo, version.String())
+	}
+
+	if err := pool.AddInstance(instance.Name, client); err != nil {
+		GetSugar().Errorf("%s, 状态: 连接失败，后台将定期重试 - %v", instanceInfo, err)
+		// 保留在连接池中等待后台重连，避免Zabbix短暂不可用时实例直到重启都无法使用
+		if pendingErr := pool.AddPendingInstance(instance.Name, client, err); pendingErr == nil && instance.Default {
+			pool.SetDefault(instance.Name)
+		}
+		return err
+	}
+
+	if instance.Default {
+		pool.SetDefault(instance.Name)
+	}
+
+	GetSugar().Infof("%s, 状态: 连接成功", instanceInfo)
+	return nil
+}
+
+// newZabbixClient 根据实例配置创建Zabbix客户端（认证、重试、TLS、代理、熔断器等）
+func newZabbixClient(instance ZabbixInstance) (*zabbix.ZabbixClient, error) {
+	client := zabbix.NewZabbixClient(instance.URL, instance.User, instance.Pass)
+	client.SetName(instance.Name)
+
+	// 设置认证方式
+	if instance.AuthType == "token" && instance.Token != "" {
+		client.SetAuthToken(instance.Token)
+	}
+
+	// 设置重试策略
+	if instance.Retry != nil {
+		policy := zabbix.DefaultRetryPolicy()
+		policy.MaxAttempts = instance.Retry.MaxAttempts
+		if instance.Retry.BaseDelay > 0 {
+			policy.BaseDelay = instance.Retry.BaseDelay
+		}
+		if instance.Retry.MaxDelay > 0 {
+			policy.MaxDelay = instance.Retry.MaxDelay
+		}
+		client.SetRetryPolicy(policy)
+	}
+
+	// 设置TLS
+	if instance.TLS != nil {
+		if instance.TLS.InsecureSkipVerify {
+			GetSugar().Warnf("!!! 实例 %s 已启用 insecure_skip_verify，将不校验服务器证书，连接可能被中间人攻击，请勿在生产环境使用 !!!", instance.Name)
+		}
+		if err := client.SetTLSConfig(zabbix.TLSOptions{
+			CAFile:             instance.TLS.CAFile,
+			CertFile:           instance.TLS.CertFile,
+			KeyFile:            instance.TLS.KeyFile,
+			ServerName:         instance.TLS.ServerName,
+			MinVersion:         instance.TLS.MinVersion,
+			InsecureSkipVerify: instance.TLS.InsecureSkipVerify,
+		}); err != nil {
+			return nil, fmt.Errorf("TLS配置失败: %w", err)
+		}
+	}
+
+	// 设置代理
+	if instance.ProxyURL != "" {
+		if err := client.SetProxy(instance.ProxyURL); err != nil {
+			return nil, fmt.Errorf("代理配置失败: %w", err)
+		}
+	}
+
+	// 设置额外请求头部
+	if len(instance.ExtraHeaders) > 0 {
+		client.SetExtraHeaders(instance.ExtraHeaders)
+	}
+
+	// 设置熔断器
+	if instance.CircuitBreaker != nil {
+		client.SetCircuitBreaker(zabbix.NewCircuitBreaker(
+			instance.CircuitBreaker.FailureThreshold, instance.CircuitBreaker.OpenTimeout))
+	}
+
+	return client, nil
+}
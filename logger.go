@@ -3,8 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
+	"zabbix-mcp-go/redact"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -15,18 +15,14 @@ var (
 	sugar  *zap.SugaredLogger
 )
 
-// InitLogger 初始化日志记录器
-func InitLogger() error {
-	// 创建logs目录
-	logsDir := "logs"
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
-		return fmt.Errorf("创建日志目录失败: %v", err)
+// InitLogger 根据配置初始化日志记录器。
+// stdio 为 true 表示stdout被MCP协议占用，此时控制台日志不会写入stdout，配置为stdout时改写stderr。
+func InitLogger(cfg LogConfig, stdio bool) error {
+	level, err := parseLogLevel(cfg.Level)
+	if err != nil {
+		return err
 	}
 
-	// 获取当前日期
-	currentDate := time.Now().Format("2006-01-02")
-	logFile := filepath.Join(logsDir, fmt.Sprintf("zabbix-mcp-%s.log", currentDate))
-
 	// 配置日志编码器
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "timestamp",
@@ -43,28 +39,91 @@ func InitLogger() error {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	// 创建JSON编码器
-	jsonEncoder := zapcore.NewJSONEncoder(encoderConfig)
+	// 创建编码器
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case "", "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return fmt.Errorf("不支持的日志格式: %s", cfg.Format)
+	}
+
+	var cores []zapcore.Core
 
 	// 创建文件写入器
-	fileWriter := zapcore.AddSync(&dateRotatingWriter{
-		filename: logFile,
-		file:     nil,
-	})
+	if !cfg.DisableFile {
+		logsDir := cfg.Dir
+		if logsDir == "" {
+			logsDir = "logs"
+		}
+		if err := os.MkdirAll(logsDir, 0755); err != nil {
+			return fmt.Errorf("创建日志目录失败: %v", err)
+		}
 
-	// 创建控制台写入器
-	consoleWriter := zapcore.Lock(os.Stdout)
+		fileWriter := &dateRotatingWriter{
+			dir:        logsDir,
+			maxSize:    int64(cfg.MaxSizeMB) * 1024 * 1024,
+			maxAge:     time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+			maxBackups: cfg.MaxBackups,
+			compress:   cfg.Compress,
+		}
+		cores = append(cores, zapcore.NewCore(encoder, fileWriter, level))
+	}
+
+	// 创建控制台写入器，stdio模式下stdout只能输出MCP协议消息
+	redirected := false
+	switch cfg.Console {
+	case "", "stderr":
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), level))
+	case "stdout":
+		if stdio {
+			redirected = true
+			cores = append(cores, zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), level))
+		} else {
+			cores = append(cores, zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), level))
+		}
+	case "none":
+	default:
+		return fmt.Errorf("不支持的控制台日志输出位置: %s", cfg.Console)
+	}
 
 	// 创建多写入器（同时写入文件和控制台）
-	core := zapcore.NewTee(
-		zapcore.NewCore(jsonEncoder, fileWriter, zap.InfoLevel),
-		zapcore.NewCore(jsonEncoder, consoleWriter, zap.InfoLevel),
-	)
+	core := redact.NewCore(zapcore.NewTee(cores...))
 
 	// 创建logger
 	logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 	sugar = logger.Sugar()
 
+	if redirected {
+		sugar.Warn("stdio传输方式占用stdout，控制台日志改为输出到stderr")
+	}
+
+	return nil
+}
+
+// parseLogLevel 解析日志级别，为空时返回info
+func parseLogLevel(text string) (zapcore.Level, error) {
+	if text == "" {
+		return zap.InfoLevel, nil
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return level, fmt.Errorf("不支持的日志级别: %s", text)
+	}
+	return level, nil
+}
+
+// InitCLILogger 初始化命令行工具使用的日志记录器，只输出到stderr
+func InitCLILogger() error {
+	encoderConfig := zap.NewDevelopmentEncoderConfig()
+	encoderConfig.EncodeCaller = nil
+	core := redact.NewCore(zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.Lock(os.Stderr), zap.InfoLevel))
+
+	logger = zap.New(core)
+	sugar = logger.Sugar()
+
 	return nil
 }
 
@@ -84,39 +143,3 @@ func Sync() {
 		logger.Sync()
 	}
 }
-
-// dateRotatingWriter 按日期切分的日志写入器
-type dateRotatingWriter struct {
-	filename string
-	file     *os.File
-	date     string
-}
-
-func (w *dateRotatingWriter) Write(p []byte) (n int, err error) {
-	currentDate := time.Now().Format("2006-01-02")
-
-	// 检查是否需要切换日志文件
-	if w.date != currentDate || w.file == nil {
-		if w.file != nil {
-			w.file.Close()
-		}
-
-		// 创建新的日志文件
-		newFilename := filepath.Join("logs", fmt.Sprintf("zabbix-mcp-%s.log", currentDate))
-		w.file, err = os.OpenFile(newFilename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-		if err != nil {
-			return 0, err
-		}
-		w.date = currentDate
-		w.filename = newFilename
-	}
-
-	return w.file.Write(p)
-}
-
-func (w *dateRotatingWriter) Sync() error {
-	if w.file != nil {
-		return w.file.Sync()
-	}
-	return nil
-}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logFilePrefix 日志文件名前缀，完整文件名为 zabbix-mcp-YYYY-MM-DD.log，
+// 按大小切分出的历史文件为 zabbix-mcp-YYYY-MM-DD.N.log（压缩后追加 .gz）
+const logFilePrefix = "zabbix-mcp-"
+
+// dateRotatingWriter 按日期切分的日志写入器，可选按大小切分、压缩和清理历史文件
+type dateRotatingWriter struct {
+	dir        string        // 日志目录
+	maxSize    int64         // 单个文件最大字节数，0表示不限制
+	maxAge     time.Duration // 历史文件保留时长，0表示不按时间清理
+	maxBackups int           // 历史文件保留个数，0表示不按个数清理
+	compress   bool          // 是否gzip压缩历史文件
+
+	mu       sync.Mutex
+	file     *os.File
+	filename string
+	date     string
+	size     int64
+
+	// millMu 串行化后台的压缩和清理，避免两次切分同时处理同一个文件
+	millMu sync.Mutex
+}
+
+// Write 实现io.Writer，日期变化或超过大小限制时切换到新文件
+func (w *dateRotatingWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	currentDate := time.Now().Format("2006-01-02")
+
+	// 检查是否需要切换日志文件
+	if w.date != currentDate || w.file == nil {
+		if err := w.openLocked(currentDate); err != nil {
+			return 0, err
+		}
+	} else if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync 将缓冲写入磁盘
+func (w *dateRotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+// Close 关闭当前日志文件
+func (w *dateRotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// openLocked 打开指定日期的日志文件（追加写入），并在后台处理之前的文件；调用方需持有 mu
+func (w *dateRotatingWriter) openLocked(date string) error {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+
+	filename := filepath.Join(w.dir, fmt.Sprintf("%s%s.log", logFilePrefix, date))
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.filename = filename
+	w.date = date
+	w.size = info.Size()
+
+	// 前一天的文件以及上次运行遗留的文件都在这里压缩和清理
+	go w.mill(filename)
+	return nil
+}
+
+// rotateLocked 当前文件超过大小限制，重命名为带序号的历史文件后重新打开；调用方需持有 mu
+func (w *dateRotatingWriter) rotateLocked() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	if err := os.Rename(w.filename, w.backupName()); err != nil {
+		return err
+	}
+	return w.openLocked(w.date)
+}
+
+// backupName 返回当天的下一个历史文件名，序号比已有的最大序号大1，
+// 保证序号越大文件越新（被清理掉的序号不会复用）
+func (w *dateRotatingWriter) backupName() string {
+	next := 1
+	for _, name := range w.historyFiles(w.filename) {
+		if date, index, ok := parseLogName(filepath.Base(name)); ok && date == w.date && index >= next {
+			next = index + 1
+		}
+	}
+	return fmt.Sprintf("%s.%d.log", strings.TrimSuffix(w.filename, ".log"), next)
+}
+
+// mill 压缩并清理除 active 以外的历史日志文件，失败时写stderr（日志本身不可用）
+func (w *dateRotatingWriter) mill(active string) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	if w.compress {
+		for _, name := range w.historyFiles(active) {
+			if strings.HasSuffix(name, ".log") {
+				if err := gzipFile(name); err != nil {
+					fmt.Fprintf(os.Stderr, "压缩日志文件 %s 失败: %v\n", name, err)
+				}
+			}
+		}
+	}
+
+	if w.maxAge <= 0 && w.maxBackups <= 0 {
+		return
+	}
+
+	type history struct {
+		name    string
+		date    string
+		index   int
+		modTime time.Time
+	}
+	var files []history
+	for _, name := range w.historyFiles(active) {
+		date, index, ok := parseLogName(filepath.Base(name))
+		if !ok {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		files = append(files, history{name: name, date: date, index: index, modTime: info.ModTime()})
+	}
+	// 最新的排在前面：日期越晚越新；同一天内不带序号的是当天最后写入的文件，其余序号越大越新
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].date != files[j].date {
+			return files[i].date > files[j].date
+		}
+		if files[i].index == 0 || files[j].index == 0 {
+			return files[i].index == 0 && files[j].index != 0
+		}
+		return files[i].index > files[j].index
+	})
+
+	cutoff := time.Now().Add(-w.maxAge)
+	for i, file := range files {
+		expired := w.maxAge > 0 && file.modTime.Before(cutoff)
+		overflow := w.maxBackups > 0 && i >= w.maxBackups
+		if !expired && !overflow {
+			continue
+		}
+		if err := os.Remove(file.name); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "删除过期日志文件 %s 失败: %v\n", file.name, err)
+		}
+	}
+}
+
+// historyFiles 列出日志目录中除 active 以外的日志文件
+func (w *dateRotatingWriter) historyFiles(active string) []string {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if _, _, ok := parseLogName(name); !ok {
+			continue
+		}
+		path := filepath.Join(w.dir, name)
+		if path == active {
+			continue
+		}
+		names = append(names, path)
+	}
+	return names
+}
+
+// parseLogName 解析日志文件名，返回日期和序号（不带序号时为0）
+func parseLogName(name string) (date string, index int, ok bool) {
+	if !strings.HasPrefix(name, logFilePrefix) {
+		return "", 0, false
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, logFilePrefix), ".gz")
+	if !strings.HasSuffix(name, ".log") {
+		return "", 0, false
+	}
+	name = strings.TrimSuffix(name, ".log")
+
+	date, suffix, hasIndex := strings.Cut(name, ".")
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", 0, false
+	}
+	if hasIndex {
+		index, err := strconv.Atoi(suffix)
+		if err != nil || index < 1 {
+			return "", 0, false
+		}
+		return date, index, true
+	}
+	return date, 0, true
+}
+
+// gzipFile 将文件压缩为同名 .gz 文件，成功后删除原文件
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+
+	// 保留原文件的修改时间，按时间清理时以写入时间为准
+	os.Chtimes(name+".gz", info.ModTime(), info.ModTime())
+	return os.Remove(name)
+}
@@ -1,9 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"zabbix-mcp-go/audit"
 	"zabbix-mcp-go/handler"
+	"zabbix-mcp-go/metrics"
 	"zabbix-mcp-go/zabbix"
 
 	"github.com/mark3labs/mcp-go/server"
@@ -13,69 +24,107 @@ var (
 	pool *zabbix.ZabbixPool
 )
 
+// DefaultShutdownTimeout 收到退出信号后等待HTTP请求完成的默认时间
+const DefaultShutdownTimeout = 10 * time.Second
+
 func main() {
+	// 子命令：校验配置文件
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		os.Exit(runCheckConfig(os.Args[2:]))
+	}
+
 	// 定义命令行参数
 	var (
 		stdioMode = flag.Bool("stdio", false, "使用stdio传输方式")
 		httpMode  = flag.Bool("http", false, "使用HTTP/SSE传输方式")
-		port      = flag.Int("port", 5443, "HTTP/SSE监听端口")
+		transport = flag.String("transport", "", "传输方式：stdio、sse 或 streamable-http，覆盖 -stdio、-http 和配置文件中的 transport")
+		port      = flag.Int("port", 5443, "HTTP监听端口，配置了 -listen 或 http.listen 时不使用")
+		listen    = flag.String("listen", "", "HTTP监听地址，如 127.0.0.1:8080，覆盖配置文件中的 http.listen")
+		baseURL   = flag.String("base-url", "", "客户端访问本服务的外部地址，如 https://mcp.example.com，覆盖配置文件中的 http.base_url")
+		basePath  = flag.String("base-path", "", "HTTP端点的路径前缀，如 /zabbix，覆盖配置文件中的 http.base_path")
+		tlsCert   = flag.String("tls-cert", "", "HTTPS证书文件，覆盖配置文件中的 http.tls.cert_file")
+		tlsKey    = flag.String("tls-key", "", "HTTPS私钥文件，覆盖配置文件中的 http.tls.key_file")
+		config    = flag.String("config", "", "配置文件路径，默认读取环境变量"+ConfigPathEnv+"，否则为"+DefaultConfigPath)
+		reload    = flag.Duration("reload-interval", 5*time.Second, "配置文件变化检查间隔，0表示只在收到SIGHUP时重新加载")
+		logLevel  = flag.String("log-level", "", "日志级别（debug、info、warn、error），覆盖配置文件中的 log.level")
 	)
 	flag.Parse()
 
-	// 初始化日志
-	if err := InitLogger(); err != nil {
+	// 加载配置前只输出到stderr，避免污染stdio传输
+	if err := InitCLILogger(); err != nil {
 		panic("初始化日志失败: " + err.Error())
 	}
-	defer Sync()
-
-	GetSugar().Info("启动Zabbix MCP服务器")
 
 	// 加载配置
-	if err := LoadConfig(); err != nil {
+	configPath := ResolveConfigPath(*config)
+	if err := LoadConfig(configPath); err != nil {
 		GetSugar().Fatalf("加载配置失败: %v", err)
 	}
-	GetSugar().Info("配置加载成功")
+
+	// 确定传输方式：-transport 优先，其次 -stdio、-http，最后是配置文件；都没有时同时启动stdio和SSE
+	switch {
+	case *transport != "":
+	case *stdioMode:
+		*transport = TransportStdio
+	case *httpMode:
+		*transport = TransportSSE
+	default:
+		*transport = AppConfig.Transport
+	}
+	switch *transport {
+	case "", TransportStdio, TransportSSE, TransportStreamableHTTP:
+	default:
+		GetSugar().Fatalf("不支持的传输方式: %s，只能是 %s、%s 或 %s", *transport, TransportStdio, TransportSSE, TransportStreamableHTTP)
+	}
+	useStdio := *transport == "" || *transport == TransportStdio
+
+	// 按配置初始化日志，stdio传输方式下不写stdout
+	logConfig := AppConfig.Log
+	if *logLevel != "" {
+		logConfig.Level = *logLevel
+	}
+	if err := InitLogger(logConfig, useStdio); err != nil {
+		GetSugar().Fatalf("初始化日志失败: %v", err)
+	}
+	defer Sync()
+	zabbix.SetLogger(GetSugar())
+
+	// 初始化链路追踪
+	shutdownTracing, err := initTracing(AppConfig.Tracing, AppConfig.Log.Dir, useStdio)
+	if err != nil {
+		GetSugar().Fatalf("初始化链路追踪失败: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// 打开审计日志
+	if err := initAudit(AppConfig.Audit, AppConfig.Log.Dir); err != nil {
+		GetSugar().Fatalf("初始化审计日志失败: %v", err)
+	}
+	defer audit.Close()
+
+	GetSugar().Info("启动Zabbix MCP服务器")
+	GetSugar().Infof("配置加载成功: %s", configPath)
 
 	// 初始化Zabbix连接池
 	pool = zabbix.NewZabbixPool()
+	metrics.MustRegister(newPoolCollector(pool))
 	// 收集成功连接的实例名称
 	successfulInstances := make([]string, 0)
 
 	for _, instance := range AppConfig.Instances {
-		client := zabbix.NewZabbixClient(instance.URL, instance.User, instance.Pass)
-
-		// 设置认证方式
-		if instance.AuthType == "token" && instance.Token != "" {
-			client.SetAuthToken(instance.Token)
-		}
-
-		// 获取实例信息
-		instanceInfo := fmt.Sprintf("实例名称: %s, 地址: %s, 认证方式: %s",
-			instance.Name, instance.URL, instance.AuthType)
-
-		// 检测Zabbix版本
-		detector := zabbix.NewVersionDetector(client)
-		version, err := detector.DetectVersion()
-		if err != nil {
-			GetSugar().Warnf("%s, 状态: 版本检测失败 - %v", instanceInfo, err)
-		} else {
-			instanceInfo = fmt.Sprintf("%s, Zabbix版本: %s", instanceInfo, version.String())
-		}
-
-		if err := pool.AddInstance(instance.Name, client); err != nil {
-			GetSugar().Errorf("%s, 状态: 连接失败 - %v", instanceInfo, err)
+		if err := connectInstance(instance); err != nil {
 			continue
 		}
-
-		if instance.Default {
-			pool.SetDefault(instance.Name)
-		}
-
-		GetSugar().Infof("%s, 状态: 连接成功", instanceInfo)
 		successfulInstances = append(successfulInstances, instance.Name)
 	}
 	GetSugar().Infof("Zabbix连接池初始化完成，成功连接 %d 个实例: %v", len(successfulInstances), successfulInstances)
 
+	// 后台定期检查实例健康状态，供 /readyz 和指标读取
+	pool.StartHealthMonitor(context.Background(), AppConfig.Health.Interval, AppConfig.Health.Timeout)
+
+	// 监听配置文件变化，无需重启即可增删实例或轮换凭据
+	startConfigReloader(configPath, *reload)
+
 	// 设置handler包的依赖
 	handler.SetDependencies(pool, GetSugar(), MustJSON, func(client interface{}) handler.ZabbixClient {
 		if zabbixClient, ok := client.(*zabbix.ZabbixClient); ok {
@@ -95,39 +144,160 @@ func main() {
 	RegisterTools(s)
 	GetSugar().Info("工具注册完成")
 
+	// 命令行参数覆盖配置文件中的HTTP设置
+	httpConfig := AppConfig.HTTP
+	if *listen != "" {
+		httpConfig.Listen = *listen
+	} else if httpConfig.Listen == "" {
+		httpConfig.Listen = fmt.Sprintf(":%d", *port)
+	}
+	if *baseURL != "" {
+		httpConfig.BaseURL = *baseURL
+	}
+	if *basePath != "" {
+		if !strings.HasPrefix(*basePath, "/") {
+			GetSugar().Fatalf("-base-path 必须以 / 开头: %s", *basePath)
+		}
+		httpConfig.BasePath = *basePath
+	}
+	if *tlsCert != "" || *tlsKey != "" {
+		if *tlsCert == "" || *tlsKey == "" {
+			GetSugar().Fatal("-tls-cert 和 -tls-key 必须同时指定")
+		}
+		httpConfig.TLS.CertFile = *tlsCert
+		httpConfig.TLS.KeyFile = *tlsKey
+	}
+
+	// 收到SIGTERM或SIGINT时优雅退出
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
 	// 根据参数选择传输方式
-	if *stdioMode {
+	switch *transport {
+	case TransportStdio:
 		// 启动stdio服务器
 		GetSugar().Info("启动stdio传输方式的MCP服务器...")
-		if err := server.ServeStdio(s); err != nil {
+		if err := serveStdio(ctx, s); err != nil {
 			GetSugar().Fatalf("stdio服务器启动失败: %v", err)
 		}
-	} else if *httpMode {
-		// 启动HTTP/SSE服务器
-		startHTTPServer(s, *port)
-	} else {
+	case TransportSSE, TransportStreamableHTTP:
+		// 启动HTTP服务器
+		if err := startHTTPServer(ctx, s, httpConfig, *transport); err != nil {
+			GetSugar().Fatalf("HTTP服务器启动失败: %v", err)
+		}
+	default:
 		// 默认同时启动两种方式（在不同的goroutine中）
 		GetSugar().Info("同时启动stdio和HTTP/SSE传输方式的MCP服务器...")
 
 		// 在后台启动HTTP服务器
-		go startHTTPServer(s, *port)
+		httpDone := make(chan struct{})
+		go func() {
+			defer close(httpDone)
+			if err := startHTTPServer(ctx, s, httpConfig, TransportSSE); err != nil {
+				GetSugar().Fatalf("HTTP/SSE服务器启动失败: %v", err)
+			}
+		}()
 
-		// 在主线程启动stdio服务器
-		if err := server.ServeStdio(s); err != nil {
+		// 在主线程启动stdio服务器，stdio结束后同样关闭HTTP服务器
+		err := serveStdio(ctx, s)
+		stop()
+		<-httpDone
+		if err != nil {
 			GetSugar().Fatalf("stdio服务器启动失败: %v", err)
 		}
 	}
+	GetSugar().Info("Zabbix MCP服务器已退出")
 }
 
-// startHTTPServer 启动HTTP传输服务器（使用SSE）
-func startHTTPServer(s *server.MCPServer, port int) {
-	addr := fmt.Sprintf(":%d", port)
-	GetSugar().Infof("启动HTTP/SSE传输服务器，监听端口: %d", port)
-	GetSugar().Infof("MCP端点: http://localhost:%d", port)
+// serveStdio 启动stdio传输，ctx 结束或stdin关闭时返回。
+// 与 server.ServeStdio 相同，只是在ctx中带上固定的会话ID，使 switch_instance 对stdio客户端同样生效。
+func serveStdio(ctx context.Context, s *server.MCPServer) error {
+	stdioServer := server.NewStdioServer(s)
+	stdioServer.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	err := stdioServer.Listen(handler.WithSession(ctx, handler.StdioSessionID), os.Stdin, os.Stdout)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// startHTTPServer 启动HTTP传输服务器，transport 为 sse 时提供 /sse 和 /message，为 streamable-http 时提供 /mcp，
+// 同一端口提供 /metrics、/healthz 和 /readyz。配置了TLS证书时使用HTTPS，配置了认证时只有MCP端点需要认证。
+// ctx 结束时停止接受新连接，等待进行中的消息处理完成并关闭所有会话后返回。
+func startHTTPServer(ctx context.Context, s *server.MCPServer, httpConfig HTTPConfig, transport string) error {
+	tlsConfig, err := newServerTLSConfig(httpConfig.TLS)
+	if err != nil {
+		return fmt.Errorf("TLS配置错误: %w", err)
+	}
+
+	auth := newHTTPAuth(httpConfig)
+	if !auth.enabled {
+		GetSugar().Warn("HTTP未启用认证，任何能访问该端口的客户端都可以调用所有工具")
+	}
+
+	basePath := strings.TrimSuffix(httpConfig.BasePath, "/")
+	mux := http.NewServeMux()
+
+	// 长连接不会自己结束，退出时先由传输层关闭会话，Shutdown才能等到所有连接空闲
+	var shutdownTransport func(context.Context)
+	endpoint := basePath + "/sse"
+	if transport == TransportStreamableHTTP {
+		streamableServer := newStreamableServer(s, auth)
+		endpoint = basePath + "/mcp"
+		mux.Handle(endpoint, streamableServer)
+		shutdownTransport = streamableServer.shutdown
+	} else {
+		sseServer := newSSEServer(s, httpConfig.BaseURL, basePath, auth)
+		mux.HandleFunc(basePath+"/sse", sseServer.handleSSE)
+		mux.HandleFunc(basePath+"/message", sseServer.handleMessage)
+		shutdownTransport = sseServer.shutdown
+	}
+	mux.Handle(basePath+"/metrics", metrics.Handler())
+	mux.HandleFunc(basePath+"/healthz", handleHealthz)
+	mux.HandleFunc(basePath+"/readyz", handleReadyz)
+
+	httpServer := &http.Server{
+		Addr:      httpConfig.Listen,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+
+	GetSugar().Infof("启动HTTP传输服务器（%s），监听地址: %s", transport, httpConfig.Listen)
+	if httpConfig.BaseURL != "" {
+		GetSugar().Infof("MCP端点: %s%s", strings.TrimSuffix(httpConfig.BaseURL, "/"), endpoint)
+	} else {
+		GetSugar().Infof("MCP端点: %s", endpoint)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			serveErr <- httpServer.ListenAndServeTLS(httpConfig.TLS.CertFile, httpConfig.TLS.KeyFile)
+		} else {
+			serveErr <- httpServer.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout := httpConfig.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	GetSugar().Infof("正在关闭HTTP服务器，最长等待 %v", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// 使用v0.9.0版本支持的API：创建SSE服务器
-	sseServer := server.NewSSEServer(s, fmt.Sprintf("http://localhost:%d", port))
-	if err := sseServer.Start(addr); err != nil {
-		GetSugar().Fatalf("HTTP/SSE服务器启动失败: %v", err)
+	go shutdownTransport(shutdownCtx)
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		GetSugar().Warnf("HTTP服务器未能在 %v 内完全关闭: %v", timeout, err)
+		httpServer.Close()
 	}
+	GetSugar().Info("HTTP服务器已关闭")
+	return nil
 }
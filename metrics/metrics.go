@@ -0,0 +1,117 @@
+// Package metrics 定义服务暴露的Prometheus指标，通过 /metrics 端点输出
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "zabbix_mcp"
+
+// registry 独立的注册表，避免引入全局默认注册表中其他库注册的指标
+var registry = prometheus.NewRegistry()
+
+var (
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "MCP工具调用次数，按工具名称和结果（success/error）统计",
+	}, []string{"tool", "status"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "MCP工具调用耗时",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"tool"})
+
+	apiCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "zabbix_api_calls_total",
+		Help:      "Zabbix API调用次数，code 为 ok、JSON-RPC错误码或其他错误类别",
+	}, []string{"instance", "method", "code"})
+
+	apiDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "zabbix_api_call_duration_seconds",
+		Help:      "Zabbix API调用耗时（含重试）",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 120},
+	}, []string{"instance", "method"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "zabbix_logins_total",
+		Help:      "Zabbix登录次数，按结果（success/error）统计",
+	}, []string{"instance", "result"})
+
+	relogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "zabbix_relogins_total",
+		Help:      "会话失效后自动重新登录的次数",
+	}, []string{"instance"})
+
+	versionDetections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "zabbix_version_detections_total",
+		Help:      "Zabbix版本检测（apiinfo.version）次数，按结果（success/error）统计",
+	}, []string{"instance", "result"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		toolCalls, toolDuration,
+		apiCalls, apiDuration,
+		logins, relogins, versionDetections,
+	)
+}
+
+// Handler 返回 /metrics 端点的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// MustRegister 注册额外的采集器，例如连接池健康状态
+func MustRegister(cs ...prometheus.Collector) {
+	registry.MustRegister(cs...)
+}
+
+// ObserveToolCall 记录一次MCP工具调用
+func ObserveToolCall(tool string, success bool, duration time.Duration) {
+	toolCalls.WithLabelValues(tool, status(success)).Inc()
+	toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// ObserveAPICall 记录一次Zabbix API调用
+func ObserveAPICall(instance, method, code string, duration time.Duration) {
+	apiCalls.WithLabelValues(instance, method, code).Inc()
+	apiDuration.WithLabelValues(instance, method).Observe(duration.Seconds())
+}
+
+// ObserveLogin 记录一次登录
+func ObserveLogin(instance string, success bool) {
+	logins.WithLabelValues(instance, status(success)).Inc()
+}
+
+// ObserveRelogin 记录一次会话失效后的重新登录
+func ObserveRelogin(instance string) {
+	relogins.WithLabelValues(instance).Inc()
+}
+
+// ObserveVersionDetection 记录一次版本检测
+func ObserveVersionDetection(instance string, success bool) {
+	versionDetections.WithLabelValues(instance, status(success)).Inc()
+}
+
+// status 将成功与否转换为标签值
+func status(success bool) string {
+	if success {
+		return "success"
+	}
+	return "error"
+}
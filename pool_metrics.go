@@ -0,0 +1,70 @@
+package main
+
+import (
+	"zabbix-mcp-go/zabbix"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector 在抓取时读取连接池中各实例的状态，不发起任何API调用
+type poolCollector struct {
+	pool *zabbix.ZabbixPool
+
+	instances           *prometheus.Desc
+	connected           *prometheus.Desc
+	healthy             *prometheus.Desc
+	circuitState        *prometheus.Desc
+	consecutiveFailures *prometheus.Desc
+}
+
+// newPoolCollector 创建连接池状态采集器
+func newPoolCollector(pool *zabbix.ZabbixPool) *poolCollector {
+	return &poolCollector{
+		pool: pool,
+		instances: prometheus.NewDesc("zabbix_mcp_pool_instances",
+			"连接池中的实例数量", nil, nil),
+		connected: prometheus.NewDesc("zabbix_mcp_instance_connected",
+			"实例是否持有有效会话（1为是）", []string{"instance"}, nil),
+		healthy: prometheus.NewDesc("zabbix_mcp_instance_healthy",
+			"最近一次后台健康检查是否通过（1为是）", []string{"instance"}, nil),
+		circuitState: prometheus.NewDesc("zabbix_mcp_instance_circuit_state",
+			"实例熔断器状态，当前状态对应的序列为1", []string{"instance", "state"}, nil),
+		consecutiveFailures: prometheus.NewDesc("zabbix_mcp_instance_consecutive_failures",
+			"实例连续失败次数", []string{"instance"}, nil),
+	}
+}
+
+// Describe 实现prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.instances
+	ch <- c.connected
+	ch <- c.healthy
+	ch <- c.circuitState
+	ch <- c.consecutiveFailures
+}
+
+// Collect 实现prometheus.Collector
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.GetInstanceStats()
+	ch <- prometheus.MustNewConstMetric(c.instances, prometheus.GaugeValue, float64(len(stats)))
+
+	health := c.pool.CachedHealth()
+	for _, stat := range stats {
+		ch <- prometheus.MustNewConstMetric(c.connected, prometheus.GaugeValue, boolValue(stat.Connected), stat.Name)
+		if status, checked := health[stat.Name]; checked {
+			ch <- prometheus.MustNewConstMetric(c.healthy, prometheus.GaugeValue, boolValue(status.Healthy), stat.Name)
+		}
+		ch <- prometheus.MustNewConstMetric(c.consecutiveFailures, prometheus.GaugeValue, float64(stat.ConsecutiveFailures), stat.Name)
+		for _, state := range []string{"closed", "open", "half-open"} {
+			ch <- prometheus.MustNewConstMetric(c.circuitState, prometheus.GaugeValue, boolValue(stat.CircuitState == state), stat.Name, state)
+		}
+	}
+}
+
+// boolValue 将布尔值转换为指标值
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package redact
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// core 包装zapcore.Core，写入前屏蔽日志消息和字段中的敏感信息
+type core struct {
+	zapcore.Core
+}
+
+// NewCore 返回屏蔽敏感信息的zapcore.Core，所有日志输出（文件、控制台）都应经过它
+func NewCore(c zapcore.Core) zapcore.Core {
+	return &core{Core: c}
+}
+
+// With 实现zapcore.Core，附加的字段同样需要屏蔽
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(redactFields(fields))}
+}
+
+// Check 实现zapcore.Core，确保写入时经过本层而不是被包装的Core
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 实现zapcore.Core
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = String(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+// redactFields 屏蔽字符串、错误、Stringer以及参数map类型的字段
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	out := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = String(field.String)
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: String(err.Error())}
+			}
+		case zapcore.StringerType:
+			if stringer, ok := field.Interface.(fmt.Stringer); ok {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: String(stringer.String())}
+			}
+		case zapcore.ReflectType:
+			if args, ok := field.Interface.(map[string]interface{}); ok {
+				field.Interface = Args(args)
+			} else {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: String(fmt.Sprintf("%+v", field.Interface))}
+			}
+		}
+		out[i] = field
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+// Package redact 在日志输出前屏蔽密码、token等敏感信息
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask 替换敏感内容使用的占位符
+const Mask = "******"
+
+// minSecretLen 注册的密钥短于该长度时不做全文替换，避免误伤普通文本
+const minSecretLen = 4
+
+var (
+	secrets   = make(map[string]int)
+	secretsMu sync.RWMutex
+)
+
+var (
+	// bearerPattern 匹配 Authorization 头部中的 Bearer token
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s"',;]+`)
+
+	// keyValuePattern 匹配 key: value、key=value、"key":"value" 形式中名称像密钥的字段，
+	// 同时覆盖 %+v 打印的 map[password:xxx] 和JSON-RPC请求体中的 "auth"
+	keyValuePattern = regexp.MustCompile(`(?i)("?\b(?:auth|[\w-]*(?:password|passwd|token|secret|authorization|api[_-]?key|credential)[\w-]*)"?\s*[:=]\s*)("[^"]*"|[^\s,}\]&"]+)`)
+
+	// userinfoPattern 匹配URL中的 user:password@
+	userinfoPattern = regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`)
+
+	// secretMacroPattern 匹配 %+v 打印的 map[macro:{$X} type:1 value:xxx] 和JSON中secret类型宏的值，
+	// 两种形式的键都按字母顺序排列，type 后紧跟 value
+	secretMacroPattern = regexp.MustCompile(`(\btype"?\s*[:=]\s*"?1"?[,\s]\s*"?value"?\s*[:=]\s*)("[^"]*"|[^,}\]]+)`)
+
+	// sensitiveKeyPattern 判断参数名是否属于敏感字段
+	sensitiveKeyPattern = regexp.MustCompile(`(?i)^(?:auth|.*(?:password|passwd|token|secret|authorization|api[_-]?key|credential).*)$`)
+)
+
+// Register 登记一个密钥，之后日志中出现该值时都会被屏蔽。
+// 同一个值可以多次登记，需要对应次数的 Unregister 才会移除。
+func Register(secret string) {
+	if len(secret) < minSecretLen {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secrets[secret]++
+}
+
+// Unregister 移除登记的密钥，例如会话登出或凭据轮换后
+func Unregister(secret string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if secrets[secret] <= 1 {
+		delete(secrets, secret)
+		return
+	}
+	secrets[secret]--
+}
+
+// IsSensitiveKey 判断参数名是否像密码、token或secret
+func IsSensitiveKey(key string) bool {
+	return sensitiveKeyPattern.MatchString(key)
+}
+
+// String 屏蔽文本中已登记的密钥、Bearer token、敏感字段和secret类型宏的值以及URL中的密码
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+
+	secretsMu.RLock()
+	if len(secrets) > 0 {
+		// 长的先替换，避免一个密钥是另一个的子串时只替换了一部分
+		known := make([]string, 0, len(secrets))
+		for secret := range secrets {
+			known = append(known, secret)
+		}
+		sort.Slice(known, func(i, j int) bool { return len(known[i]) > len(known[j]) })
+		for _, secret := range known {
+			s = strings.ReplaceAll(s, secret, Mask)
+		}
+	}
+	secretsMu.RUnlock()
+
+	s = bearerPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = keyValuePattern.ReplaceAllString(s, "${1}"+Mask)
+	s = secretMacroPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = userinfoPattern.ReplaceAllString(s, "${1}"+Mask+"@")
+	return s
+}
+
+// Args 返回参数的副本，敏感字段和secret类型宏的值被屏蔽，原参数不变
+func Args(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	return redactValue(args).(map[string]interface{})
+}
+
+// redactValue 递归屏蔽map和slice中的敏感值
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		secretMacro := isSecretMacro(v)
+		for key, item := range v {
+			switch {
+			case IsSensitiveKey(key):
+				out[key] = Mask
+			case secretMacro && key == "value":
+				out[key] = Mask
+			default:
+				out[key] = redactValue(item)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item)
+		}
+		return out
+	case string:
+		return String(v)
+	default:
+		return value
+	}
+}
+
+// isSecretMacro 判断是否为Zabbix的secret类型宏（type为1）
+func isSecretMacro(m map[string]interface{}) bool {
+	if _, ok := m["macro"]; !ok {
+		return false
+	}
+	return fmt.Sprint(m["type"]) == "1"
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"time"
+	"zabbix-mcp-go/handler"
+	"zabbix-mcp-go/zabbix"
+)
+
+// fileStamp 文件的修改时间和大小，用于判断文件是否变化
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// configReloader 监听配置文件（及其引用的密钥文件）变化和SIGHUP信号，把新配置同步到连接池
+type configReloader struct {
+	path     string
+	interval time.Duration
+	stamps   map[string]fileStamp
+}
+
+// startConfigReloader 在后台启动配置热加载；interval 为0时只响应SIGHUP，不轮询文件
+func startConfigReloader(path string, interval time.Duration) {
+	r := &configReloader{path: path, interval: interval}
+	r.stamps = r.snapshot(AppConfig)
+	go r.run()
+}
+
+func (r *configReloader) run() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	GetSugar().Infof("配置热加载已启用: %s（轮询间隔: %v，也可发送SIGHUP触发）", r.path, r.interval)
+
+	for {
+		select {
+		case <-hup:
+			GetSugar().Info("收到SIGHUP信号，重新加载配置")
+			r.reload()
+		case <-tick:
+			if r.changed() {
+				GetSugar().Info("检测到配置文件变化，重新加载配置")
+				r.reload()
+			}
+		}
+	}
+}
+
+// watchedFiles 需要监听的文件：配置文件本身和各实例的 password_file/token_file
+func (r *configReloader) watchedFiles(config Config) []string {
+	files := []string{r.path}
+	baseDir := filepath.Dir(r.path)
+	for _, instance := range config.Instances {
+		for _, secretFile := range []string{instance.PasswordFile, instance.TokenFile} {
+			if secretFile == "" {
+				continue
+			}
+			if !filepath.IsAbs(secretFile) {
+				secretFile = filepath.Join(baseDir, secretFile)
+			}
+			files = append(files, secretFile)
+		}
+	}
+	return files
+}
+
+// snapshot 记录被监听文件的当前状态
+func (r *configReloader) snapshot(config Config) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, file := range r.watchedFiles(config) {
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+// changed 判断被监听的文件是否有变化
+func (r *configReloader) changed() bool {
+	return !reflect.DeepEqual(r.snapshot(AppConfig), r.stamps)
+}
+
+// reload 重新读取并校验配置，校验失败时保留当前配置
+func (r *configReloader) reload() {
+	newConfig, err := ReadConfig(r.path)
+	if err == nil {
+		err = newConfig.Validate()
+	}
+	if err != nil {
+		GetSugar().Errorf("重新加载配置失败，继续使用当前配置: %v", err)
+		// 记录当前状态，避免对同一个错误文件反复报错
+		r.stamps = r.snapshot(AppConfig)
+		return
+	}
+
+	if newConfig.Log != AppConfig.Log {
+		GetSugar().Warn("配置热加载: 日志配置已修改，需要重启后生效")
+	}
+	if newConfig.Tracing != AppConfig.Tracing {
+		GetSugar().Warn("配置热加载: 链路追踪配置已修改，需要重启后生效")
+	}
+	if newConfig.Audit != AppConfig.Audit {
+		GetSugar().Warn("配置热加载: 审计日志配置已修改，需要重启后生效")
+	}
+	if newConfig.Health != AppConfig.Health {
+		GetSugar().Warn("配置热加载: 健康检查配置已修改，需要重启后生效")
+	}
+	if newConfig.Transport != AppConfig.Transport {
+		GetSugar().Warn("配置热加载: 传输方式已修改，需要重启后生效")
+	}
+	if !reflect.DeepEqual(newConfig.HTTP, AppConfig.HTTP) {
+		GetSugar().Warn("配置热加载: HTTP认证和TLS配置已修改，需要重启后生效")
+	}
+
+	// 调用时立即按新策略检查，但工具列表在启动时注册，新增可见的工具需要重启
+	newPolicy := newToolPolicy(*newConfig)
+	if !reflect.DeepEqual(newPolicy, newToolPolicy(AppConfig)) {
+		GetSugar().Warn("配置热加载: 工具访问策略已修改，调用时立即生效，客户端看到的工具列表需要重启后更新")
+	}
+	handler.SetToolPolicy(newPolicy)
+
+	applyConfig(AppConfig, *newConfig)
+	setAppConfig(*newConfig)
+	r.stamps = r.snapshot(AppConfig)
+}
+
+// applyConfig 对比新旧配置并同步到连接池：新增、移除、凭据更新或重建实例，
+// 未变化的实例保持原有会话不受影响
+func applyConfig(oldConfig, newConfig Config) {
+	oldInstances := make(map[string]ZabbixInstance)
+	for _, instance := range oldConfig.Instances {
+		oldInstances[instance.Name] = instance
+	}
+	newInstances := make(map[string]ZabbixInstance)
+	for _, instance := range newConfig.Instances {
+		newInstances[instance.Name] = instance
+	}
+
+	// 移除配置中已删除的实例
+	for _, name := range pool.GetInstanceNames() {
+		if _, exists := newInstances[name]; !exists {
+			if err := pool.RemoveInstance(name); err != nil {
+				GetSugar().Errorf("配置热加载: 移除实例 %s 失败: %v", name, err)
+				continue
+			}
+			GetSugar().Infof("配置热加载: 已移除实例 %s", name)
+		}
+	}
+
+	for _, instance := range newConfig.Instances {
+		client := pool.GetZabbixClient(instance.Name)
+
+		// 新增实例，或配置错误未加入连接池的实例
+		if client == nil {
+			if err := connectInstance(instance); err == nil {
+				GetSugar().Infof("配置热加载: 已添加实例 %s", instance.Name)
+			}
+			continue
+		}
+
+		// default 的变化在最后统一处理，required 只影响 /readyz，tools 只影响工具访问策略，都不影响实例连接
+		oldInstance := oldInstances[instance.Name]
+		oldInstance.Default = instance.Default
+		oldInstance.Required = instance.Required
+		oldInstance.Tools = instance.Tools
+		if reflect.DeepEqual(oldInstance, instance) {
+			continue
+		}
+
+		// 尚未连接成功的实例没有需要保留的会话，直接按新配置重新连接
+		if status, exists := pool.InstanceHealth(instance.Name); exists && status.State == zabbix.StatePending {
+			if err := pool.RemoveInstance(instance.Name); err != nil {
+				GetSugar().Errorf("配置热加载: 移除待重连实例 %s 失败: %v", instance.Name, err)
+				continue
+			}
+			if err := connectInstance(instance); err == nil {
+				GetSugar().Infof("配置热加载: 已按新配置连接实例 %s", instance.Name)
+			}
+			continue
+		}
+
+		// 只有凭据变化时原地更新，保留客户端的版本缓存和熔断器状态
+		if reflect.DeepEqual(withoutCredentials(oldInstance), withoutCredentials(instance)) {
+			// 新凭据登录成功后才登出旧会话，失败时继续使用旧凭据
+			if err := client.RotateCredentials(context.Background(), instance.User, instance.Pass, instance.AuthType, instance.Token); err != nil {
+				GetSugar().Errorf("配置热加载: 实例 %s 使用新凭据登录失败，保留原有会话: %v", instance.Name, err)
+				continue
+			}
+			GetSugar().Infof("配置热加载: 已更新实例 %s 的凭据", instance.Name)
+			continue
+		}
+
+		// 连接参数变化（地址、TLS、代理等），重建客户端
+		newClient, err := newZabbixClient(instance)
+		if err != nil {
+			GetSugar().Errorf("配置热加载: 实例 %s 配置错误，保留原有连接: %v", instance.Name, err)
+			continue
+		}
+		if err := pool.ReplaceInstance(instance.Name, newClient); err != nil {
+			GetSugar().Errorf("配置热加载: 重建实例 %s 失败，保留原有连接: %v", instance.Name, err)
+			continue
+		}
+		GetSugar().Infof("配置热加载: 已按新的连接参数重建实例 %s", instance.Name)
+	}
+
+	// 同步默认实例
+	for _, instance := range newConfig.Instances {
+		if instance.Default && pool.GetDefaultInstanceName() != instance.Name {
+			if err := pool.SetDefault(instance.Name); err == nil {
+				GetSugar().Infof("配置热加载: 默认实例切换为 %s", instance.Name)
+			}
+		}
+	}
+}
+
+// withoutCredentials 返回去掉认证相关字段后的实例配置，用于判断是否只有凭据发生了变化
+func withoutCredentials(instance ZabbixInstance) ZabbixInstance {
+	instance.User = ""
+	instance.Pass = ""
+	instance.Token = ""
+	instance.AuthType = ""
+	instance.PasswordFile = ""
+	instance.TokenFile = ""
+	instance.passwordFromFile = false
+	instance.tokenFromFile = false
+	return instance
+}
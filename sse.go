@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"zabbix-mcp-go/handler"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sseServer 与mcp-go的SSE传输协议兼容：GET /sse 建立事件流并返回消息端点，
+// POST /message?sessionId=xxx 提交JSON-RPC请求，响应通过事件流返回。
+// mcp-go v0.9.0 的 SSEServer 自己创建监听，无法与 /metrics 等端点共用端口，因此在这里实现。
+type sseServer struct {
+	mcpServer *server.MCPServer
+	baseURL   string // 为空时根据请求的Host生成
+	basePath  string
+	auth      *httpAuth
+	sessions  sync.Map // sessionID -> *sseSession
+	drain     *drainGuard
+}
+
+// sseSession 一个SSE连接，所有写入都由建立连接的goroutine完成
+type sseSession struct {
+	events    chan []byte
+	done      chan struct{}
+	principal *handler.Principal // 建立连接的调用方，后续消息必须来自同一调用方
+}
+
+// newSSEServer 创建SSE传输，baseURL 和 basePath 用于拼接返回给客户端的消息端点地址
+func newSSEServer(s *server.MCPServer, baseURL, basePath string, auth *httpAuth) *sseServer {
+	return &sseServer{
+		mcpServer: s,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		basePath:  basePath,
+		auth:      auth,
+		drain:     newDrainGuard(),
+	}
+}
+
+// messageEndpoint 返回给客户端的消息端点地址
+func (s *sseServer) messageEndpoint(r *http.Request, sessionID string) string {
+	baseURL := s.baseURL
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+	return fmt.Sprintf("%s%s/message?sessionId=%s", baseURL, s.basePath, sessionID)
+}
+
+// shutdown 停止接受新的会话和消息，等待进行中的消息处理完成（最长到ctx结束），然后关闭所有事件流
+func (s *sseServer) shutdown(ctx context.Context) {
+	if !s.drain.shutdown(ctx) {
+		GetSugar().Warn("等待进行中的SSE消息处理超时，强制关闭事件流")
+	}
+}
+
+// handleSSE 建立SSE连接，连接断开前持续把响应写给客户端
+func (s *sseServer) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.drain.isClosing() {
+		http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	// 认证失败时不建立会话
+	principal, err := s.auth.authenticate(r)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	sessionID := uuid.New().String()
+	session := &sseSession{
+		events:    make(chan []byte, 100),
+		done:      make(chan struct{}),
+		principal: principal,
+	}
+	s.sessions.Store(sessionID, session)
+	defer func() {
+		s.sessions.Delete(sessionID)
+		handler.EndSession(sessionID)
+		close(session.done)
+	}()
+
+	fmt.Fprintf(w, "event: endpoint\ndata: %s\r\n\r\n", s.messageEndpoint(r, sessionID))
+	flusher.Flush()
+
+	for {
+		select {
+		case event := <-session.events:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+			flusher.Flush()
+		case <-s.drain.done():
+			// 服务器退出前把已产生的响应写完
+			for {
+				select {
+				case event := <-session.events:
+					fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+				default:
+					flusher.Flush()
+					return
+				}
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleMessage 处理客户端提交的JSON-RPC消息，响应同时写入事件流和HTTP响应体
+func (s *sseServer) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONRPCError(w, http.StatusMethodNotAllowed, mcp.INVALID_REQUEST, "Method not allowed")
+		return
+	}
+
+	if !s.drain.acquire() {
+		writeJSONRPCError(w, http.StatusServiceUnavailable, mcp.INTERNAL_ERROR, "Server shutting down")
+		return
+	}
+	defer s.drain.release()
+
+	principal, err := s.auth.authenticate(r)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("sessionId")
+	if sessionID == "" {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_PARAMS, "Missing sessionId")
+		return
+	}
+	value, ok := s.sessions.Load(sessionID)
+	if !ok {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_PARAMS, "Invalid session ID")
+		return
+	}
+	session := value.(*sseSession)
+	if !samePrincipal(session.principal, principal) {
+		writeAuthError(w, r, &authError{http.StatusForbidden, "会话属于其他客户端"})
+		return
+	}
+
+	var rawMessage json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&rawMessage); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.PARSE_ERROR, "Parse error")
+		return
+	}
+
+	ctx := handler.WithPrincipal(handler.WithSession(r.Context(), sessionID), principal)
+	response := s.mcpServer.HandleMessage(ctx, rawMessage)
+	if response == nil {
+		// 通知类消息没有响应
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	eventData, err := json.Marshal(response)
+	if err != nil {
+		writeJSONRPCError(w, http.StatusInternalServerError, mcp.INTERNAL_ERROR, "Marshal error")
+		return
+	}
+	select {
+	case session.events <- eventData:
+	case <-session.done:
+	case <-r.Context().Done():
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(eventData)
+}
+
+// writeJSONRPCError 写入JSON-RPC错误响应
+func writeJSONRPCError(w http.ResponseWriter, status, code int, message string) {
+	response := mcp.JSONRPCError{JSONRPC: mcp.JSONRPC_VERSION}
+	response.Error.Code = code
+	response.Error.Message = message
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
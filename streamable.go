@@ -0,0 +1,501 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"zabbix-mcp-go/handler"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// sessionIDHeader 传递会话ID的请求/响应头
+	sessionIDHeader = "Mcp-Session-Id"
+	// lastEventIDHeader 客户端重连时携带的最后一个事件ID
+	lastEventIDHeader = "Last-Event-ID"
+
+	// streamableHistorySize 每个会话保留的事件数，用于断线后重放
+	streamableHistorySize = 1000
+	// streamableSessionIdleTimeout 没有连接和请求的会话超过该时间后清理
+	streamableSessionIdleTimeout = 30 * time.Minute
+	// streamableKeepAlive GET事件流的保活间隔，避免被代理断开
+	streamableKeepAlive = 30 * time.Second
+)
+
+// streamableServer MCP Streamable HTTP传输（2025-03-26版协议），所有消息通过同一个端点交互：
+// POST 提交JSON-RPC消息（可批量），请求的响应以JSON或SSE事件流返回；
+// GET 打开SSE事件流，携带 Last-Event-ID 时重放断开后产生的事件；DELETE 结束会话。
+// 会话ID在 initialize 的响应头 Mcp-Session-Id 中分配，之后的请求都需要携带。
+type streamableServer struct {
+	mcpServer *server.MCPServer
+	auth      *httpAuth
+	sessions  sync.Map // sessionID -> *streamableSession
+	drain     *drainGuard
+}
+
+// streamEvent 事件流中的一条消息
+type streamEvent struct {
+	id     int64
+	stream int64
+	data   []byte
+}
+
+// streamableSession 一个Streamable HTTP会话，保存最近的事件以便客户端断线后重放
+type streamableSession struct {
+	id        string
+	principal *handler.Principal // 创建会话的调用方，后续请求必须来自同一调用方
+	// ctx 会话结束时取消。消息在会话的ctx中处理，客户端断线不会中止进行中的工具调用，结果可以通过重连取回
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	lastID     int64 // 事件ID和流ID共用一个递增序列
+	events     []streamEvent
+	open       map[int64]bool // 尚未结束的事件流
+	changed    chan struct{}  // 有新事件或流结束时关闭并替换
+	lastActive time.Time
+}
+
+// newStreamableServer 创建Streamable HTTP传输，并在后台清理空闲会话
+func newStreamableServer(s *server.MCPServer, auth *httpAuth) *streamableServer {
+	st := &streamableServer{
+		mcpServer: s,
+		auth:      auth,
+		drain:     newDrainGuard(),
+	}
+	go st.expireSessions()
+	return st
+}
+
+// ServeHTTP 按请求方法分发
+func (s *streamableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodGet:
+		s.handleGet(w, r)
+	case http.MethodDelete:
+		s.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeJSONRPCError(w, http.StatusMethodNotAllowed, mcp.INVALID_REQUEST, "Method not allowed")
+	}
+}
+
+// handlePost 处理客户端提交的消息。只有通知时返回202；包含请求时，
+// 客户端接受 text/event-stream 则以事件流逐条返回响应，否则返回JSON
+func (s *streamableServer) handlePost(w http.ResponseWriter, r *http.Request) {
+	if !s.drain.acquire() {
+		writeJSONRPCError(w, http.StatusServiceUnavailable, mcp.INTERNAL_ERROR, "Server shutting down")
+		return
+	}
+	defer s.drain.release()
+
+	principal, err := s.auth.authenticate(r)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.PARSE_ERROR, "Parse error")
+		return
+	}
+	messages, batch, err := splitBatch(body)
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_REQUEST, err.Error())
+		return
+	}
+
+	hasRequest, initialize := false, false
+	for _, message := range messages {
+		var base struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.Unmarshal(message, &base); err != nil {
+			writeJSONRPCError(w, http.StatusBadRequest, mcp.PARSE_ERROR, "Parse error")
+			return
+		}
+		if base.Method != "" && len(base.ID) > 0 && string(base.ID) != "null" {
+			hasRequest = true
+		}
+		if base.Method == "initialize" {
+			initialize = true
+		}
+	}
+
+	// initialize 创建新会话，其他消息必须属于已有会话
+	var session *streamableSession
+	if initialize {
+		if len(messages) > 1 {
+			writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_REQUEST, "initialize不能与其他消息一起批量发送")
+			return
+		}
+		session = s.newSession(principal)
+		w.Header().Set(sessionIDHeader, session.id)
+		GetSugar().Infof("Streamable HTTP会话已建立: %s", session.id)
+	} else if session = s.lookup(w, r, principal); session == nil {
+		return
+	}
+	ctx := handler.WithPrincipal(handler.WithSession(session.ctx, session.id), principal)
+
+	// 只有通知（或客户端对服务端请求的响应）时不需要返回内容
+	if !hasRequest {
+		for _, message := range messages {
+			s.mcpServer.HandleMessage(ctx, message)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		s.streamResponses(ctx, w, r, session, messages)
+		return
+	}
+
+	var responses []mcp.JSONRPCMessage
+	for _, message := range messages {
+		if response := s.mcpServer.HandleMessage(ctx, message); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(responses)
+	} else {
+		json.NewEncoder(w).Encode(responses[0])
+	}
+}
+
+// streamResponses 以事件流逐条返回响应。每个事件都记录在会话中，
+// 客户端中途断开时继续处理剩余消息，重连后通过 Last-Event-ID 取回
+func (s *streamableServer) streamResponses(ctx context.Context, w http.ResponseWriter, r *http.Request, session *streamableSession, messages []json.RawMessage) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONRPCError(w, http.StatusInternalServerError, mcp.INTERNAL_ERROR, "Streaming unsupported")
+		return
+	}
+
+	stream := session.newStream()
+	defer session.finish(stream)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	connected := true
+	for _, message := range messages {
+		response := s.mcpServer.HandleMessage(ctx, message)
+		if response == nil {
+			continue
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			GetSugar().Errorf("序列化响应失败: %v", err)
+			continue
+		}
+		event := session.append(stream, data)
+		if connected && r.Context().Err() == nil {
+			if writeStreamEvent(w, event) != nil {
+				connected = false
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleGet 打开事件流。携带 Last-Event-ID 时先重放该事件所在流之后的事件，流已结束则返回
+func (s *streamableServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		writeJSONRPCError(w, http.StatusNotAcceptable, mcp.INVALID_REQUEST, "Accept must include text/event-stream")
+		return
+	}
+	if s.drain.isClosing() {
+		writeJSONRPCError(w, http.StatusServiceUnavailable, mcp.INTERNAL_ERROR, "Server shutting down")
+		return
+	}
+
+	principal, err := s.auth.authenticate(r)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+	session := s.lookup(w, r, principal)
+	if session == nil {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONRPCError(w, http.StatusInternalServerError, mcp.INTERNAL_ERROR, "Streaming unsupported")
+		return
+	}
+
+	var stream, after int64
+	if lastEventID := r.Header.Get(lastEventIDHeader); lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err == nil {
+			stream, ok = session.streamOf(id)
+		}
+		if err != nil || !ok {
+			GetSugar().Warnf("会话 %s 的事件 %s 已不在历史记录中，无法重放", session.id, lastEventID)
+			writeJSONRPCError(w, http.StatusNotFound, mcp.INVALID_REQUEST, "Event not found")
+			return
+		}
+		after = id
+	} else {
+		stream = session.newStream()
+		defer session.finish(stream)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamableKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		events, open, changed := session.eventsAfter(stream, after)
+		for _, event := range events {
+			if writeStreamEvent(w, event) != nil {
+				return
+			}
+			after = event.id
+		}
+		flusher.Flush()
+		if !open {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-session.ctx.Done():
+			return
+		case <-s.drain.done():
+			return
+		}
+	}
+}
+
+// handleDelete 客户端主动结束会话
+func (s *streamableServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.drain.acquire() {
+		writeJSONRPCError(w, http.StatusServiceUnavailable, mcp.INTERNAL_ERROR, "Server shutting down")
+		return
+	}
+	defer s.drain.release()
+
+	principal, err := s.auth.authenticate(r)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+	session := s.lookup(w, r, principal)
+	if session == nil {
+		return
+	}
+	s.endSession(session)
+	GetSugar().Infof("Streamable HTTP会话已结束: %s", session.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newSession 创建会话
+func (s *streamableServer) newSession(principal *handler.Principal) *streamableSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &streamableSession{
+		id:         uuid.New().String(),
+		principal:  principal,
+		ctx:        ctx,
+		cancel:     cancel,
+		open:       make(map[int64]bool),
+		changed:    make(chan struct{}),
+		lastActive: time.Now(),
+	}
+	s.sessions.Store(session.id, session)
+	return session
+}
+
+// lookup 根据请求头查找会话，找不到或不属于当前调用方时写入错误响应并返回nil
+func (s *streamableServer) lookup(w http.ResponseWriter, r *http.Request, principal *handler.Principal) *streamableSession {
+	sessionID := r.Header.Get(sessionIDHeader)
+	if sessionID == "" {
+		writeJSONRPCError(w, http.StatusBadRequest, mcp.INVALID_REQUEST, "Missing "+sessionIDHeader+" header")
+		return nil
+	}
+	value, ok := s.sessions.Load(sessionID)
+	if !ok {
+		// 按协议返回404，客户端收到后重新initialize
+		writeJSONRPCError(w, http.StatusNotFound, mcp.INVALID_REQUEST, "Session not found")
+		return nil
+	}
+	session := value.(*streamableSession)
+	if !samePrincipal(session.principal, principal) {
+		writeAuthError(w, r, &authError{http.StatusForbidden, "会话属于其他客户端"})
+		return nil
+	}
+	session.touch()
+	return session
+}
+
+// endSession 结束会话，中止进行中的工具调用并清理该会话选择的实例
+func (s *streamableServer) endSession(session *streamableSession) {
+	s.sessions.Delete(session.id)
+	session.cancel()
+	handler.EndSession(session.id)
+}
+
+// expireSessions 定期清理空闲会话，服务器退出时停止
+func (s *streamableServer) expireSessions() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.drain.done():
+			return
+		case <-ticker.C:
+		}
+		s.sessions.Range(func(_, value interface{}) bool {
+			session := value.(*streamableSession)
+			if session.idleSince(streamableSessionIdleTimeout) {
+				s.endSession(session)
+				GetSugar().Infof("Streamable HTTP会话空闲超时，已清理: %s", session.id)
+			}
+			return true
+		})
+	}
+}
+
+// shutdown 停止接受新的消息，等待进行中的消息处理完成（最长到ctx结束），然后结束所有会话
+func (s *streamableServer) shutdown(ctx context.Context) {
+	if !s.drain.shutdown(ctx) {
+		GetSugar().Warn("等待进行中的Streamable HTTP消息处理超时，强制结束会话")
+	}
+	s.sessions.Range(func(_, value interface{}) bool {
+		s.endSession(value.(*streamableSession))
+		return true
+	})
+}
+
+// newStream 开始一个事件流，返回流ID
+func (s *streamableSession) newStream() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	s.open[s.lastID] = true
+	s.lastActive = time.Now()
+	return s.lastID
+}
+
+// append 在事件流中追加一个事件，超过 streamableHistorySize 时丢弃最早的事件
+func (s *streamableSession) append(stream int64, data []byte) streamEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	event := streamEvent{id: s.lastID, stream: stream, data: data}
+	s.events = append(s.events, event)
+	if len(s.events) > streamableHistorySize {
+		s.events = append([]streamEvent(nil), s.events[len(s.events)-streamableHistorySize:]...)
+	}
+	s.notifyLocked()
+	return event
+}
+
+// finish 事件流结束
+func (s *streamableSession) finish(stream int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.open, stream)
+	s.lastActive = time.Now()
+	s.notifyLocked()
+}
+
+// notifyLocked 唤醒等待新事件的连接，调用方需持有 s.mu
+func (s *streamableSession) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// streamOf 查找事件所属的流
+func (s *streamableSession) streamOf(eventID int64) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.events {
+		if event.id == eventID {
+			return event.stream, true
+		}
+	}
+	return 0, false
+}
+
+// eventsAfter 返回流中ID大于after的事件、流是否尚未结束，以及下次变化时关闭的channel
+func (s *streamableSession) eventsAfter(stream, after int64) ([]streamEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []streamEvent
+	for _, event := range s.events {
+		if event.stream == stream && event.id > after {
+			events = append(events, event)
+		}
+	}
+	return events, s.open[stream], s.changed
+}
+
+// touch 记录会话活动时间
+func (s *streamableSession) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastActive = time.Now()
+}
+
+// idleSince 会话没有打开的事件流且超过timeout没有请求
+func (s *streamableSession) idleSince(timeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.open) == 0 && time.Since(s.lastActive) > timeout
+}
+
+// splitBatch 拆分JSON-RPC批量消息，batch 表示请求体是数组
+func splitBatch(body json.RawMessage) (messages []json.RawMessage, batch bool, err error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return []json.RawMessage{body}, false, nil
+	}
+	if err := json.Unmarshal(trimmed, &messages); err != nil {
+		return nil, true, fmt.Errorf("Parse error")
+	}
+	if len(messages) == 0 {
+		return nil, true, fmt.Errorf("Empty batch")
+	}
+	return messages, true, nil
+}
+
+// acceptsEventStream 客户端是否接受SSE事件流
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// writeStreamEvent 写入一个带ID的SSE事件
+func writeStreamEvent(w http.ResponseWriter, event streamEvent) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", event.id, event.data)
+	return err
+}
@@ -7,6 +7,26 @@ import (
 )
 
 func RegisterTools(s *server.MCPServer) {
+	// 注册前设置工具访问策略，被禁用的工具不会注册
+	handler.SetToolPolicy(newToolPolicy(CurrentConfig()))
+
 	// 使用handler包中的注册函数
 	handler.RegisterTools(s)
 }
+
+// newToolPolicy 根据配置生成工具访问策略
+func newToolPolicy(config Config) handler.ToolPolicy {
+	policy := handler.ToolPolicy{
+		ReadOnly:  config.ReadOnly,
+		Instances: make(map[string]handler.InstanceToolPolicy, len(config.Instances)),
+	}
+	for _, instance := range config.Instances {
+		var instancePolicy handler.InstanceToolPolicy
+		if instance.Tools != nil {
+			instancePolicy.Allow = instance.Tools.Allow
+			instancePolicy.Deny = instance.Tools.Deny
+		}
+		policy.Instances[instance.Name] = instancePolicy
+	}
+	return policy
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"zabbix-mcp-go/handler"
+)
+
+// FieldError 单个配置字段的校验错误
+type FieldError struct {
+	Path    string // 字段路径，如 instances[0].url
+	Message string
+}
+
+// Error 实现error接口
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors 配置校验发现的全部问题
+type ValidationErrors []FieldError
+
+// Error 实现error接口，每个问题占一行
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("配置校验失败，共 %d 个问题:", len(e)))
+	for _, fieldErr := range e {
+		lines = append(lines, "  - "+fieldErr.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate 校验配置，一次性返回所有问题；没有问题时返回nil
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	add := func(path, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(c.Instances) == 0 {
+		add("instances", "至少需要配置一个Zabbix实例")
+	}
+
+	names := make(map[string]int)
+	var defaults []string
+	for i, instance := range c.Instances {
+		path := fmt.Sprintf("instances[%d]", i)
+
+		// 名称
+		if instance.Name == "" {
+			add(path+".name", "不能为空")
+		} else if first, exists := names[instance.Name]; exists {
+			add(path+".name", "与 instances[%d] 重名: %s", first, instance.Name)
+		} else {
+			names[instance.Name] = i
+		}
+
+		if instance.Default {
+			defaults = append(defaults, path)
+		}
+
+		// 地址
+		if instance.URL == "" {
+			add(path+".url", "不能为空")
+		} else if u, err := url.Parse(instance.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(path+".url", "必须是 http:// 或 https:// 开头的完整地址: %s", instance.URL)
+		}
+
+		// 认证
+		if instance.PasswordFile != "" && !instance.passwordFromFile {
+			add(path+".password_file", "不能与 password 同时配置")
+		}
+		if instance.TokenFile != "" && !instance.tokenFromFile {
+			add(path+".token_file", "不能与 token 同时配置")
+		}
+		switch instance.AuthType {
+		case "", "password":
+			if instance.User == "" {
+				add(path+".username", "密码认证需要配置用户名")
+			}
+			if instance.Pass == "" {
+				add(path+".password", "密码认证需要配置 password 或 password_file")
+			}
+		case "token":
+			if instance.Token == "" {
+				add(path+".token", "auth_type 为 token 时需要配置 token 或 token_file")
+			}
+		default:
+			add(path+".auth_type", "只能是 password 或 token，当前为: %s", instance.AuthType)
+		}
+
+		// 重试
+		if retry := instance.Retry; retry != nil {
+			if retry.MaxAttempts < 1 {
+				add(path+".retry.max_attempts", "必须大于等于1（1表示不重试）")
+			}
+			if retry.BaseDelay < 0 {
+				add(path+".retry.base_delay", "不能为负数")
+			}
+			if retry.MaxDelay < 0 {
+				add(path+".retry.max_delay", "不能为负数")
+			}
+			if retry.BaseDelay > 0 && retry.MaxDelay > 0 && retry.BaseDelay > retry.MaxDelay {
+				add(path+".retry.base_delay", "不能大于 max_delay")
+			}
+		}
+
+		// 熔断器
+		if breaker := instance.CircuitBreaker; breaker != nil {
+			if breaker.FailureThreshold < 0 {
+				add(path+".circuit_breaker.failure_threshold", "不能为负数")
+			}
+			if breaker.OpenTimeout < 0 {
+				add(path+".circuit_breaker.open_timeout", "不能为负数")
+			}
+		}
+
+		// TLS
+		if tlsConfig := instance.TLS; tlsConfig != nil {
+			if (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+				add(path+".tls", "cert_file 和 key_file 必须同时配置")
+			}
+			switch tlsConfig.MinVersion {
+			case "", "1.0", "1.1", "1.2", "1.3":
+			default:
+				add(path+".tls.min_version", "只能是 1.0、1.1、1.2 或 1.3，当前为: %s", tlsConfig.MinVersion)
+			}
+		}
+
+		// 工具策略
+		if tools := instance.Tools; tools != nil {
+			for j, name := range tools.Allow {
+				if !handler.IsKnownTool(name) {
+					add(fmt.Sprintf("%s.tools.allow[%d]", path, j), "未知的工具: %s", name)
+				}
+			}
+			for j, name := range tools.Deny {
+				if !handler.IsKnownTool(name) {
+					add(fmt.Sprintf("%s.tools.deny[%d]", path, j), "未知的工具: %s", name)
+				}
+			}
+		}
+
+		// 代理
+		if instance.ProxyURL != "" {
+			u, err := url.Parse(instance.ProxyURL)
+			if err != nil || u.Host == "" {
+				add(path+".proxy_url", "不是有效的地址: %s", instance.ProxyURL)
+			} else if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+				add(path+".proxy_url", "只支持 http、https 和 socks5 协议，当前为: %s", u.Scheme)
+			}
+		}
+	}
+
+	if len(defaults) > 1 {
+		add("instances", "只能有一个实例设置 default: true，当前为: %s", strings.Join(defaults, ", "))
+	}
+
+	// 日志
+	if _, err := parseLogLevel(c.Log.Level); err != nil {
+		add("log.level", "只能是 debug、info、warn 或 error，当前为: %s", c.Log.Level)
+	}
+	switch c.Log.Format {
+	case "", "json", "console":
+	default:
+		add("log.format", "只能是 json 或 console，当前为: %s", c.Log.Format)
+	}
+	switch c.Log.Console {
+	case "", "stderr", "stdout", "none":
+	default:
+		add("log.console", "只能是 stderr、stdout 或 none，当前为: %s", c.Log.Console)
+	}
+	if c.Log.MaxSizeMB < 0 {
+		add("log.max_size_mb", "不能为负数")
+	}
+	if c.Log.MaxAgeDays < 0 {
+		add("log.max_age_days", "不能为负数")
+	}
+	if c.Log.MaxBackups < 0 {
+		add("log.max_backups", "不能为负数")
+	}
+	if c.Log.DisableFile && c.Log.Console == "none" {
+		add("log", "disable_file 为 true 时 console 不能为 none，否则日志没有任何输出")
+	}
+
+	// 健康检查
+	if c.Health.Interval < 0 {
+		add("health.interval", "不能为负数")
+	}
+	if c.Health.Timeout < 0 {
+		add("health.timeout", "不能为负数")
+	}
+
+	// 链路追踪
+	switch c.Tracing.Exporter {
+	case "", "file", "stdout", "stderr", "otlp":
+	default:
+		add("tracing.exporter", "只能是 file、stdout、stderr 或 otlp，当前为: %s", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio", "必须在0到1之间，当前为: %v", c.Tracing.SampleRatio)
+	}
+
+	// 传输方式
+	switch c.Transport {
+	case "", TransportStdio, TransportSSE, TransportStreamableHTTP:
+	default:
+		add("transport", "只能是 %s、%s 或 %s，当前为: %s", TransportStdio, TransportSSE, TransportStreamableHTTP, c.Transport)
+	}
+
+	// HTTP/SSE
+	if c.HTTP.BaseURL != "" {
+		if u, err := url.Parse(c.HTTP.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("http.base_url", "必须是 http:// 或 https:// 开头的完整地址: %s", c.HTTP.BaseURL)
+		}
+	}
+	if c.HTTP.BasePath != "" && !strings.HasPrefix(c.HTTP.BasePath, "/") {
+		add("http.base_path", "必须以 / 开头: %s", c.HTTP.BasePath)
+	}
+	if c.HTTP.ShutdownTimeout < 0 {
+		add("http.shutdown_timeout", "不能为负数")
+	}
+	if tlsConfig := c.HTTP.TLS; (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		add("http.tls", "cert_file 和 key_file 必须同时配置")
+	} else if tlsConfig.ClientCAFile != "" && tlsConfig.CertFile == "" {
+		add("http.tls.client_ca_file", "mTLS认证需要同时配置 cert_file 和 key_file")
+	}
+	clientNames := make(map[string]int)
+	tokens := make(map[string]int)
+	for i, client := range c.HTTP.Auth.Clients {
+		path := fmt.Sprintf("http.auth.clients[%d]", i)
+		if client.Name == "" {
+			add(path+".name", "不能为空")
+		} else if first, exists := clientNames[client.Name]; exists {
+			add(path+".name", "与 http.auth.clients[%d] 重名: %s", first, client.Name)
+		} else {
+			clientNames[client.Name] = i
+		}
+
+		if client.TokenFile != "" && !client.tokenFromFile {
+			add(path+".token_file", "不能与 token 同时配置")
+		}
+		if client.Token == "" && client.CertCommonName == "" {
+			add(path, "需要配置 token、token_file 或 cert_common_name")
+		}
+		if client.Token != "" {
+			if first, exists := tokens[client.Token]; exists {
+				add(path+".token", "与 http.auth.clients[%d] 的Token相同", first)
+			} else {
+				tokens[client.Token] = i
+			}
+		}
+		if client.CertCommonName != "" && c.HTTP.TLS.ClientCAFile == "" {
+			add(path+".cert_common_name", "需要配置 http.tls.client_ca_file 才能使用客户端证书认证")
+		}
+
+		switch client.Role {
+		case "", RoleReadWrite, RoleReadOnly:
+		default:
+			add(path+".role", "只能是 %s 或 %s，当前为: %s", RoleReadWrite, RoleReadOnly, client.Role)
+		}
+		for j, name := range client.Instances {
+			if _, exists := names[name]; !exists {
+				add(fmt.Sprintf("%s.instances[%d]", path, j), "实例不存在: %s", name)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -1,13 +1,33 @@
 package zabbix
 
 import (
+	"context"
 	"fmt"
+	"zabbix-mcp-go/metrics"
+	"zabbix-mcp-go/redact"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Login 登录Zabbix API
 func (c *ZabbixClient) Login() error {
+	return c.LoginContext(context.Background())
+}
+
+// LoginContext 登录Zabbix API（支持context取消）
+func (c *ZabbixClient) LoginContext(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	return c.login(ctx)
+}
+
+// login 使用当前凭据登录，调用方需持有 c.mu
+func (c *ZabbixClient) login(ctx context.Context) (err error) {
+	ctx, span := c.startSpan(ctx, "zabbix login", attribute.String("zabbix.auth_type", c.AuthType))
+	defer func() {
+		metrics.ObserveLogin(c.Name(), err == nil)
+		endSpan(span, err)
+	}()
 
 	// 如果已经设置了token认证，直接验证token有效性
 	if c.AuthType == "token" && c.AuthToken != "" {
@@ -17,7 +37,7 @@ func (c *ZabbixClient) Login() error {
 
 		// 尝试调用apiinfo.version来验证连接是否正常
 		// apiinfo.version不需要认证，所以传入空auth参数
-		_, err := c.call("apiinfo.version", map[string]interface{}{}, "")
+		_, err := c.call(ctx, "apiinfo.version", map[string]interface{}{}, "")
 
 		// 恢复AuthToken
 		c.AuthToken = savedToken
@@ -35,7 +55,7 @@ func (c *ZabbixClient) Login() error {
 	}
 
 	// 使用内部调用，传入空auth进行登录
-	response, err := c.callWithAuth("user.login", params, "")
+	response, err := c.callWithAuth(ctx, "user.login", params, "")
 	if err != nil {
 		return fmt.Errorf("登录失败: %w", err)
 	}
@@ -45,12 +65,18 @@ func (c *ZabbixClient) Login() error {
 		return fmt.Errorf("登录响应格式错误")
 	}
 
+	replaceSecret(c.AuthToken, authToken)
 	c.AuthToken = authToken
 	return nil
 }
 
 // Logout 登出Zabbix API
 func (c *ZabbixClient) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext 登出Zabbix API（支持context取消）
+func (c *ZabbixClient) LogoutContext(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -58,7 +84,8 @@ func (c *ZabbixClient) Logout() error {
 		return nil
 	}
 
-	_, err := c.call("user.logout", nil, c.AuthToken)
+	_, err := c.call(ctx, "user.logout", nil, c.AuthToken)
+	redact.Unregister(c.AuthToken)
 	c.AuthToken = ""
 	return err
 }
@@ -67,10 +94,59 @@ func (c *ZabbixClient) Logout() error {
 func (c *ZabbixClient) SetAuthToken(token string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	replaceSecret(c.AuthToken, token)
 	c.AuthToken = token
 	c.AuthType = "token"
 }
 
+// RotateCredentials 凭据轮换：在同一个临界区内换用新凭据登录，成功后登出旧会话。
+// 期间的API调用会等待，不会看到空的会话而用旧凭据重新登录；新凭据登录失败时保留旧凭据和会话
+func (c *ZabbixClient) RotateCredentials(ctx context.Context, user, pass, authType, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	oldUser, oldPass, oldAuthType, oldToken := c.User, c.Pass, c.AuthType, c.AuthToken
+
+	c.User = user
+	c.Pass = pass
+	if authType == "token" && token != "" {
+		c.AuthType = "token"
+		c.AuthToken = token
+	} else {
+		c.AuthType = "password"
+		c.AuthToken = ""
+	}
+
+	if err := c.login(ctx); err != nil {
+		c.User, c.Pass, c.AuthType, c.AuthToken = oldUser, oldPass, oldAuthType, oldToken
+		return err
+	}
+
+	replaceSecret(oldPass, pass)
+	if c.AuthType == "token" && c.AuthToken != oldToken {
+		redact.Register(c.AuthToken)
+	}
+	if oldToken != "" && oldToken != c.AuthToken {
+		// API token 不能登出，只有密码认证的旧会话需要登出
+		if oldAuthType != "token" {
+			if _, err := c.call(ctx, "user.logout", nil, oldToken); err != nil {
+				getLogger().Warnf("实例 %s 登出旧会话失败: %v", c.Name(), err)
+			}
+		}
+		redact.Unregister(oldToken)
+	}
+	return nil
+}
+
+// replaceSecret 从日志屏蔽列表中移除旧密钥并登记新密钥
+func replaceSecret(oldSecret, newSecret string) {
+	if oldSecret == newSecret {
+		return
+	}
+	redact.Unregister(oldSecret)
+	redact.Register(newSecret)
+}
+
 // SetAuthType 设置认证方式
 func (c *ZabbixClient) SetAuthType(authType string) {
 	c.mu.Lock()
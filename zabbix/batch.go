@@ -0,0 +1,224 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Batch JSON-RPC批量请求，把多个API调用合并为一次HTTP POST发送
+type Batch struct {
+	client  *ZabbixClient
+	entries []batchEntry
+}
+
+type batchEntry struct {
+	method string
+	params interface{}
+}
+
+// BatchResult 批量请求中单个调用的结果
+type BatchResult struct {
+	Method string
+	Result interface{}
+	Error  error
+}
+
+// NewBatch 创建批量请求
+func (c *ZabbixClient) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+// Add 添加一个API调用，返回其结果在 Execute 返回值中的下标
+func (b *Batch) Add(method string, params interface{}) int {
+	b.entries = append(b.entries, batchEntry{method: method, params: params})
+	return len(b.entries) - 1
+}
+
+// Len 获取批量请求中的调用数量
+func (b *Batch) Len() int {
+	return len(b.entries)
+}
+
+// Execute 发送批量请求，结果按 Add 的顺序返回。
+// 返回的error表示整个批量请求失败，单个调用的错误记录在对应的 BatchResult.Error 中。
+func (b *Batch) Execute(ctx context.Context) ([]BatchResult, error) {
+	if len(b.entries) == 0 {
+		return nil, nil
+	}
+
+	c := b.client
+	start := time.Now()
+	methods := make([]string, len(b.entries))
+	for i, entry := range b.entries {
+		methods[i] = entry.method
+	}
+	ctx, span := c.startSpan(ctx, "zabbix batch", attribute.StringSlice("zabbix.methods", methods))
+
+	if err := c.checkCircuit(ctx); err != nil {
+		b.observe(ctx, nil, err, start)
+		endSpan(span, err)
+		return nil, err
+	}
+
+	results, err := c.executeBatch(ctx, b.entries)
+	c.recordCircuitResult(ctx, err)
+	b.observe(ctx, results, err, start)
+	endSpan(span, err)
+	return results, err
+}
+
+// observe 按批量中的每个方法记录指标和审计日志，并在批量span下为每个方法创建子span
+func (b *Batch) observe(ctx context.Context, results []BatchResult, err error, start time.Time) {
+	for i, entry := range b.entries {
+		callErr := entryError(results, err, i)
+		b.client.observeCall(entry.method, callErr, start)
+
+		var result interface{}
+		if callErr == nil && i < len(results) {
+			result = results[i].Result
+		}
+		b.client.recordAudit(ctx, entry.method, entry.params, result, callErr, start)
+
+		// 批量中的调用共用一次HTTP请求，子span的开始时间与批量请求相同
+		_, span := tracer.Start(ctx, "zabbix "+entry.method,
+			trace.WithTimestamp(start),
+			trace.WithAttributes(
+				attribute.String("zabbix.method", entry.method),
+				attribute.Int("zabbix.batch.index", i),
+				attribute.String("zabbix.instance", b.client.Name()),
+			),
+		)
+		endSpan(span, callErr)
+	}
+}
+
+// entryError 返回批量中第i个调用的错误，单个调用的错误优先于整体错误
+func entryError(results []BatchResult, err error, i int) error {
+	if err == nil && i < len(results) && results[i].Error != nil {
+		return results[i].Error
+	}
+	return err
+}
+
+// executeBatch 确保已登录后发送批量请求；全部为只读方法时才重试和在认证失效后重发
+func (c *ZabbixClient) executeBatch(ctx context.Context, entries []batchEntry) ([]BatchResult, error) {
+	authToken, err := c.ensureAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotent := true
+	for _, entry := range entries {
+		if !IsIdempotentMethod(entry.method) {
+			idempotent = false
+			break
+		}
+	}
+
+	var results []BatchResult
+	send := func() error {
+		var err error
+		results, err = c.sendBatch(ctx, entries, authToken)
+		return err
+	}
+
+	if err := c.withRetry(ctx, idempotent, send); err != nil {
+		return nil, err
+	}
+
+	// 认证失效时重新登录并整体重发一次（仅对密码认证和只读请求）
+	if idempotent && c.AuthType != "token" && hasAuthError(results) {
+		c.InvalidateVersion()
+		if err := c.LoginContext(ctx); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		authToken = c.AuthToken
+		c.mu.Unlock()
+		if err := send(); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// sendBatch 发送一次批量请求，并按ID把响应分发回对应的调用
+func (c *ZabbixClient) sendBatch(ctx context.Context, entries []batchEntry, auth string) ([]BatchResult, error) {
+	// Zabbix 7.0+ 使用HTTP头部认证，旧版本在请求体中携带auth
+	headerAuth := false
+	if version, err := c.GetVersionContext(ctx); err == nil && version.Major >= 7 {
+		headerAuth = true
+	}
+
+	requests := make([]JSONRPCRequest, len(entries))
+	for i, entry := range entries {
+		requests[i] = JSONRPCRequest{
+			JSONRPC: "2.0",
+			Method:  entry.method,
+			Params:  entry.params,
+			ID:      i + 1,
+		}
+		if !headerAuth {
+			requests[i].Auth = auth
+		}
+	}
+
+	bearer := ""
+	if headerAuth {
+		bearer = auth
+	}
+
+	body, err := c.post(ctx, requests, bearer)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []JSONRPCResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// 整个批量请求被拒绝时Zabbix返回单个错误对象
+		var single JSONRPCResponse
+		if json.Unmarshal(body, &single) == nil && single.Error != nil {
+			return nil, single.Error
+		}
+		return nil, fmt.Errorf("解析批量响应失败: %w", err)
+	}
+
+	results := make([]BatchResult, len(entries))
+	for i, entry := range entries {
+		results[i] = BatchResult{
+			Method: entry.method,
+			Error:  fmt.Errorf("批量响应中缺少ID为 %d 的结果", i+1),
+		}
+	}
+
+	for _, response := range responses {
+		idx := response.ID - 1
+		if idx < 0 || idx >= len(results) {
+			continue
+		}
+		if response.Error != nil {
+			results[idx].Error = response.Error
+			continue
+		}
+		results[idx].Result = response.Result
+		results[idx].Error = nil
+	}
+
+	return results, nil
+}
+
+// hasAuthError 判断批量结果中是否有认证失效错误
+func hasAuthError(results []BatchResult) bool {
+	for _, result := range results {
+		if rpcErr, ok := result.Error.(*RPCError); ok && rpcErr.Code == -32602 {
+			return true
+		}
+	}
+	return false
+}
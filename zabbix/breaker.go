@@ -0,0 +1,187 @@
+package zabbix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	// CircuitClosed 正常状态，请求直接放行
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 熔断状态，请求快速失败
+	CircuitOpen
+	// CircuitHalfOpen 半开状态，正在用 apiinfo.version 探测实例是否恢复
+	CircuitHalfOpen
+)
+
+// String 返回熔断器状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// ErrCircuitOpen 熔断器打开，实例暂时不可用
+var ErrCircuitOpen = errors.New("熔断器已打开，实例暂不可用")
+
+const (
+	// DefaultFailureThreshold 默认连续失败多少次后熔断
+	DefaultFailureThreshold = 5
+	// DefaultOpenTimeout 默认熔断后多久进入半开状态进行探测
+	DefaultOpenTimeout = 30 * time.Second
+)
+
+// CircuitBreaker 实例级熔断器
+type CircuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker 创建熔断器，threshold 或 openTimeout 小于等于0时使用默认值
+func NewCircuitBreaker(threshold int, openTimeout time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = DefaultOpenTimeout
+	}
+	return &CircuitBreaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// State 获取当前状态
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// ConsecutiveFailures 获取连续失败次数
+func (cb *CircuitBreaker) ConsecutiveFailures() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.failures
+}
+
+// before 请求前检查；返回 probe=true 表示调用方需要先执行一次探测
+func (cb *CircuitBreaker) before() (probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.openTimeout {
+			return false, ErrCircuitOpen
+		}
+		// 熔断时间已过，由当前请求负责探测
+		cb.state = CircuitHalfOpen
+		return true, nil
+	case CircuitHalfOpen:
+		// 已有其他请求在探测，其余请求继续快速失败
+		return false, ErrCircuitOpen
+	}
+	return false, nil
+}
+
+// RecordSuccess 记录一次成功，熔断器恢复为关闭状态
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures = 0
+	cb.state = CircuitClosed
+}
+
+// RecordFailure 记录一次失败，连续失败达到阈值或半开探测失败时打开熔断器
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = CircuitOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+// SetCircuitBreaker 设置实例熔断器，传入nil表示禁用熔断
+func (c *ZabbixClient) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.breaker = breaker
+}
+
+// GetCircuitBreaker 获取实例熔断器
+func (c *ZabbixClient) GetCircuitBreaker() *CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.breaker
+}
+
+// checkCircuit 检查熔断器是否放行；半开状态下先用 apiinfo.version 探测实例
+func (c *ZabbixClient) checkCircuit(ctx context.Context) error {
+	breaker := c.GetCircuitBreaker()
+	if breaker == nil {
+		return nil
+	}
+
+	probe, err := breaker.before()
+	if err != nil {
+		return fmt.Errorf("%w (%s)", err, c.URL)
+	}
+	if !probe {
+		return nil
+	}
+
+	// apiinfo.version 不需要认证，直接使用内部调用
+	if _, err := c.callWithAuth(ctx, "apiinfo.version", []interface{}{}, ""); err != nil {
+		breaker.RecordFailure()
+		return fmt.Errorf("%w (%s): 探测失败: %v", ErrCircuitOpen, c.URL, err)
+	}
+	breaker.RecordSuccess()
+	return nil
+}
+
+// recordCircuitResult 根据调用结果更新熔断器
+func (c *ZabbixClient) recordCircuitResult(ctx context.Context, err error) {
+	breaker := c.GetCircuitBreaker()
+	if breaker == nil {
+		return
+	}
+
+	if err == nil {
+		breaker.RecordSuccess()
+		return
+	}
+
+	// Zabbix返回了业务错误说明实例本身可达
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		breaker.RecordSuccess()
+		return
+	}
+
+	// 调用方取消或超时不代表实例故障
+	if ctx.Err() != nil {
+		return
+	}
+
+	breaker.RecordFailure()
+}
@@ -2,6 +2,7 @@ package zabbix
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,10 +10,19 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"zabbix-mcp-go/audit"
+	"zabbix-mcp-go/metrics"
+	"zabbix-mcp-go/redact"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ZabbixClient Zabbix JSON-RPC客户端
 type ZabbixClient struct {
+	// name 实例名称，由 ZabbixPool 在添加实例时设置，用于日志和指标
+	name string
+
 	URL       string
 	User      string
 	Pass      string
@@ -24,10 +34,31 @@ type ZabbixClient struct {
 	ServerTZ   string
 	HTTPClient *http.Client
 	mu         sync.Mutex
+
+	// version 缓存检测到的Zabbix版本，避免每次API调用前都探测一次。
+	// 由 versionMu 保护，与 mu 分开，因为 Login 持有 mu 时仍需要读取版本。
+	// versionProbe 为正在进行的检测，并发的调用方等待同一次检测的结果；
+	// 检测失败后 versionRetryInterval 内直接返回上次的错误，不再重复探测。
+	version        *VersionInfo
+	versionProbe   *versionProbe
+	versionErr     error
+	versionErrTime time.Time
+	versionMu      sync.Mutex
+
+	// retryPolicy 只读类方法（*.get、apiinfo.version）的重试策略
+	retryPolicy RetryPolicy
+
+	// breaker 实例级熔断器，由 ZabbixPool 在添加实例时设置
+	breaker *CircuitBreaker
+
+	// extraHeaders 每个请求都附带的额外HTTP头部，使用独立的锁，因为 Login 持有 mu 时也会发送请求
+	extraHeaders map[string]string
+	headersMu    sync.RWMutex
 }
 
 // NewZabbixClient 创建新的Zabbix客户端
 func NewZabbixClient(url, user, pass string) *ZabbixClient {
+	redact.Register(pass)
 	return &ZabbixClient{
 		URL:      url,
 		User:     user,
@@ -37,36 +68,141 @@ func NewZabbixClient(url, user, pass string) *ZabbixClient {
 		HTTPClient: &http.Client{
 			Timeout: 120 * time.Second, // 增加到2分钟，避免复杂查询超时
 		},
+		retryPolicy: DefaultRetryPolicy(),
 	}
 }
 
+// Name 返回实例名称，未加入连接池时为空
+func (c *ZabbixClient) Name() string {
+	return c.name
+}
+
+// SetName 设置实例名称
+func (c *ZabbixClient) SetName(name string) {
+	c.name = name
+}
+
+// String 实现fmt.Stringer，打印客户端时不输出密码和token
+func (c *ZabbixClient) String() string {
+	return fmt.Sprintf("ZabbixClient{URL: %s, User: %s, AuthType: %s}", redact.String(c.URL), c.User, c.AuthType)
+}
+
 // SetServerTimezone 设置 Zabbix 服务器时区（例如 "UTC" 或 "Asia/Shanghai"）。
 // 如果不设置，默认使用本地时区解析无时区信息的时间字符串。
 func (c *ZabbixClient) SetServerTimezone(tz string) {
 	c.ServerTZ = tz
 }
 
+// GetVersion 获取Zabbix版本，首次调用时检测并缓存，之后直接返回缓存结果
+func (c *ZabbixClient) GetVersion() (*VersionInfo, error) {
+	return c.GetVersionContext(context.Background())
+}
+
+// 版本检测的超时时间和失败后的重试间隔
+const (
+	versionProbeTimeout  = 10 * time.Second
+	versionRetryInterval = 5 * time.Second
+)
+
+// versionProbe 一次版本检测，done 关闭后 version 和 err 可读
+type versionProbe struct {
+	done    chan struct{}
+	version *VersionInfo
+	err     error
+}
+
+// GetVersionContext 获取Zabbix版本（支持context取消）。
+// 检测在锁外进行，使用独立的超时；ctx 取消时只是不再等待，不影响其他等待同一次检测的调用方
+func (c *ZabbixClient) GetVersionContext(ctx context.Context) (*VersionInfo, error) {
+	c.versionMu.Lock()
+	if c.version != nil {
+		version := c.version
+		c.versionMu.Unlock()
+		return version, nil
+	}
+	if c.versionErr != nil && time.Since(c.versionErrTime) < versionRetryInterval {
+		err := c.versionErr
+		c.versionMu.Unlock()
+		return nil, err
+	}
+	probe := c.versionProbe
+	if probe == nil {
+		probe = &versionProbe{done: make(chan struct{})}
+		c.versionProbe = probe
+		// 保留ctx中的追踪信息，但不随发起检测的调用方一起取消
+		go c.detectVersion(context.WithoutCancel(ctx), probe)
+	}
+	c.versionMu.Unlock()
+
+	select {
+	case <-probe.done:
+		return probe.version, probe.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detectVersion 执行一次版本检测，结果写入缓存并通知等待的调用方。
+// 检测期间缓存被 InvalidateVersion 清除时，结果只返回给等待者，不写入缓存
+func (c *ZabbixClient) detectVersion(ctx context.Context, probe *versionProbe) {
+	ctx, cancel := context.WithTimeout(ctx, versionProbeTimeout)
+	defer cancel()
+
+	version, err := NewVersionDetector(c).DetectVersionContext(ctx)
+	metrics.ObserveVersionDetection(c.Name(), err == nil)
+
+	c.versionMu.Lock()
+	if c.versionProbe == probe {
+		c.versionProbe = nil
+		if err != nil {
+			c.versionErr = err
+			c.versionErrTime = time.Now()
+		} else {
+			c.version = version
+			c.versionErr = nil
+		}
+	}
+	c.versionMu.Unlock()
+
+	probe.version, probe.err = version, err
+	close(probe.done)
+}
+
+// RefreshVersion 丢弃缓存并重新检测Zabbix版本
+func (c *ZabbixClient) RefreshVersion() (*VersionInfo, error) {
+	c.InvalidateVersion()
+	return c.GetVersion()
+}
+
+// InvalidateVersion 清除缓存的版本信息，下次调用时重新检测
+func (c *ZabbixClient) InvalidateVersion() {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	c.version = nil
+	c.versionProbe = nil
+	c.versionErr = nil
+}
+
 // call 调用Zabbix API（内部方法）
-func (c *ZabbixClient) call(method string, params interface{}, auth string) (interface{}, error) {
-	// 检测Zabbix版本以确定认证方式
-	detector := NewVersionDetector(c)
-	version, err := detector.DetectVersion()
+func (c *ZabbixClient) call(ctx context.Context, method string, params interface{}, auth string) (interface{}, error) {
+	// 根据缓存的Zabbix版本确定认证方式
+	version, err := c.GetVersionContext(ctx)
 	if err != nil {
 		// 如果版本检测失败，使用传统方式
-		return c.callWithAuth(method, params, auth)
+		return c.callWithAuth(ctx, method, params, auth)
 	}
 
 	// Zabbix 7.0+ 不再使用auth参数，改用HTTP头部认证
 	if version.Major >= 7 {
-		return c.callWithHeaderAuth(method, params, auth)
+		return c.callWithHeaderAuth(ctx, method, params, auth)
 	}
 
 	// 旧版本使用传统的auth参数
-	return c.callWithAuth(method, params, auth)
+	return c.callWithAuth(ctx, method, params, auth)
 }
 
 // callWithAuth 传统认证方式（Zabbix 6.x及更早版本）
-func (c *ZabbixClient) callWithAuth(method string, params interface{}, auth string) (interface{}, error) {
+func (c *ZabbixClient) callWithAuth(ctx context.Context, method string, params interface{}, auth string) (interface{}, error) {
 	request := JSONRPCRequest{
 		JSONRPC: "2.0",
 		Method:  method,
@@ -75,12 +211,25 @@ func (c *ZabbixClient) callWithAuth(method string, params interface{}, auth stri
 		Auth:    auth,
 	}
 
-	requestData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	return c.doRequest(ctx, request, "")
+}
+
+// callWithHeaderAuth Zabbix 7.0+ 使用HTTP头部认证
+func (c *ZabbixClient) callWithHeaderAuth(ctx context.Context, method string, params interface{}, auth string) (interface{}, error) {
+	// Zabbix 7.0+ 不在请求体中包含auth参数
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      1,
+		// Auth字段为空，不包含在JSON中
 	}
 
-	// 构建完整的API URL，如果URL中没有包含api_jsonrpc.php则自动添加
+	return c.doRequest(ctx, request, auth)
+}
+
+// apiURL 构建完整的API URL，如果URL中没有包含api_jsonrpc.php则自动添加
+func (c *ZabbixClient) apiURL() string {
 	apiURL := c.URL
 	if !strings.Contains(apiURL, "api_jsonrpc.php") {
 		// 移除末尾的斜杠（如果有）
@@ -88,16 +237,14 @@ func (c *ZabbixClient) callWithAuth(method string, params interface{}, auth stri
 		// 添加API路径
 		apiURL = apiURL + "/api_jsonrpc.php"
 	}
+	return apiURL
+}
 
-	resp, err := c.HTTPClient.Post(apiURL, "application/json", bytes.NewBuffer(requestData))
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+// doRequest 发送单个JSON-RPC请求；bearer 非空时通过Authorization头部认证（Zabbix 7.0+）
+func (c *ZabbixClient) doRequest(ctx context.Context, request JSONRPCRequest, bearer string) (interface{}, error) {
+	body, err := c.post(ctx, request, bearer)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %w", err)
+		return nil, err
 	}
 
 	var response JSONRPCResponse
@@ -112,43 +259,32 @@ func (c *ZabbixClient) callWithAuth(method string, params interface{}, auth stri
 	return response.Result, nil
 }
 
-// callWithHeaderAuth Zabbix 7.0+ 使用HTTP头部认证
-func (c *ZabbixClient) callWithHeaderAuth(method string, params interface{}, auth string) (interface{}, error) {
-	// Zabbix 7.0+ 不在请求体中包含auth参数
-	request := JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  method,
-		Params:  params,
-		ID:      1,
-		// Auth字段为空，不包含在JSON中
-	}
-
-	requestData, err := json.Marshal(request)
+// post 将payload序列化为JSON并发送到API端点，返回响应体
+func (c *ZabbixClient) post(ctx context.Context, payload interface{}, bearer string) ([]byte, error) {
+	requestData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("序列化请求失败: %w", err)
 	}
 
-	// 构建完整的API URL，如果URL中没有包含api_jsonrpc.php则自动添加
-	apiURL := c.URL
-	if !strings.Contains(apiURL, "api_jsonrpc.php") {
-		// 移除末尾的斜杠（如果有）
-		apiURL = strings.TrimRight(apiURL, "/")
-		// 添加API路径
-		apiURL = apiURL + "/api_jsonrpc.php"
-	}
-
 	// 创建HTTP请求
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(requestData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL(), bytes.NewBuffer(requestData))
 	if err != nil {
 		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
 	}
 
+	// 先设置额外头部，再设置协议必需的头部，保证后者不会被覆盖
+	c.headersMu.RLock()
+	for k, v := range c.extraHeaders {
+		req.Header.Set(k, v)
+	}
+	c.headersMu.RUnlock()
+
 	// 设置请求头
 	req.Header.Set("Content-Type", "application/json")
 
 	// Zabbix 7.0+ 使用Authorization头部进行认证
-	if auth != "" {
-		req.Header.Set("Authorization", "Bearer "+auth)
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
 	}
 
 	// 执行请求
@@ -163,44 +299,117 @@ func (c *ZabbixClient) callWithHeaderAuth(method string, params interface{}, aut
 		return nil, fmt.Errorf("读取响应失败: %w", err)
 	}
 
-	var response JSONRPCResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %w", err)
+	// PHP执行超时时前端返回的是HTML错误页而不是JSON；正常的JSON-RPC响应中的数据（如历史值）
+	// 可能恰好包含这段文字，不能当作超时
+	failed := resp.StatusCode < 200 || resp.StatusCode >= 300
+	if (failed || !isJSONRPCResponse(body)) && bytes.Contains(body, []byte("Maximum execution time")) {
+		return nil, fmt.Errorf("%w (HTTP %d)", ErrPHPTimeout, resp.StatusCode)
 	}
 
-	if response.Error != nil {
-		return nil, response.Error
+	if failed {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Body: truncate(string(body), 200)}
 	}
 
-	return response.Result, nil
+	return body, nil
+}
+
+// isJSONRPCResponse 判断响应体是否为JSON-RPC响应（单个响应或批量响应）
+func isJSONRPCResponse(body []byte) bool {
+	var envelope struct {
+		JSONRPC string `json:"jsonrpc"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		return envelope.JSONRPC != ""
+	}
+	var batch []struct {
+		JSONRPC string `json:"jsonrpc"`
+	}
+	return json.Unmarshal(body, &batch) == nil && len(batch) > 0 && batch[0].JSONRPC != ""
 }
 
 // Call 公开API调用方法
 func (c *ZabbixClient) Call(method string, params interface{}) (interface{}, error) {
+	return c.CallContext(context.Background(), method, params)
+}
+
+// CallContext 公开API调用方法，ctx 被取消或超时时会中止正在进行的HTTP请求
+func (c *ZabbixClient) CallContext(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	start := time.Now()
+	ctx, span := c.startSpan(ctx, "zabbix "+method, attribute.String("zabbix.method", method))
+
+	// 熔断器打开时快速失败，不再等待HTTP超时
+	if err := c.checkCircuit(ctx); err != nil {
+		c.observeCall(method, err, start)
+		endSpan(span, err)
+		c.recordAudit(ctx, method, params, nil, err, start)
+		return nil, err
+	}
+
+	result, err := c.callAuthenticated(ctx, method, params)
+	c.recordCircuitResult(ctx, err)
+	c.observeCall(method, err, start)
+	endSpan(span, err)
+	c.recordAudit(ctx, method, params, result, err, start)
+	return result, err
+}
+
+// recordAudit 将写操作记录到审计日志，只读方法不记录
+func (c *ZabbixClient) recordAudit(ctx context.Context, method string, params, result interface{}, err error, start time.Time) {
+	if IsIdempotentMethod(method) {
+		return
+	}
+	if auditErr := audit.Record(ctx, c.Name(), method, params, result, err, time.Since(start)); auditErr != nil {
+		getLogger().Errorf("实例 %s 记录审计日志失败: %v", c.Name(), auditErr)
+	}
+}
+
+// ensureAuth 返回当前认证token，密码认证且尚未登录时先登录
+func (c *ZabbixClient) ensureAuth(ctx context.Context) (string, error) {
 	c.mu.Lock()
 	authToken := c.AuthToken
 	c.mu.Unlock()
 
 	if authToken == "" && c.AuthType != "token" {
-		if err := c.Login(); err != nil {
-			return nil, err
+		if err := c.LoginContext(ctx); err != nil {
+			return "", err
 		}
 		c.mu.Lock()
 		authToken = c.AuthToken
 		c.mu.Unlock()
 	}
 
-	result, err := c.call(method, params, authToken)
+	return authToken, nil
+}
+
+// callAuthenticated 确保已登录后调用API，认证失效时自动重新登录一次
+func (c *ZabbixClient) callAuthenticated(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	authToken, err := c.ensureAuth(ctx)
 	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.callWithRetry(ctx, method, params, authToken)
+	if err != nil {
+		rpcErr, isRPCErr := err.(*RPCError)
+
+		// 方法不存在时（例如服务器升级后），缓存的版本可能已过期
+		if isRPCErr && rpcErr.Code == -32601 {
+			c.InvalidateVersion()
+		}
+
 		// 如果认证失败，尝试重新登录（仅对密码认证）
-		if rpcErr, ok := err.(*RPCError); ok && rpcErr.Code == -32602 && c.AuthType != "token" {
-			if err := c.Login(); err != nil {
+		if isRPCErr && rpcErr.Code == -32602 && c.AuthType != "token" {
+			metrics.ObserveRelogin(c.Name())
+			trace.SpanFromContext(ctx).AddEvent("relogin")
+			// 会话失效通常意味着服务器重启，服务器可能已经升级，重新检测版本
+			c.InvalidateVersion()
+			if err := c.LoginContext(ctx); err != nil {
 				return nil, err
 			}
 			c.mu.Lock()
 			authToken = c.AuthToken
 			c.mu.Unlock()
-			return c.call(method, params, authToken)
+			return c.call(ctx, method, params, authToken)
 		}
 		return nil, err
 	}
@@ -210,14 +419,28 @@ func (c *ZabbixClient) Call(method string, params interface{}) (interface{}, err
 
 // GetInstanceInfo 获取实例详细信息
 func (c *ZabbixClient) GetInstanceInfo() map[string]interface{} {
+	return c.GetInstanceInfoContext(context.Background())
+}
+
+// GetInstanceInfoContext 获取实例详细信息（支持context取消）
+func (c *ZabbixClient) GetInstanceInfoContext(ctx context.Context) map[string]interface{} {
 	info := make(map[string]interface{})
 	info["url"] = c.URL
 	info["auth_type"] = c.AuthType
 	info["status"] = "unknown"
 
-	// 检测版本信息
-	detector := NewVersionDetector(c)
-	version, err := detector.DetectVersion()
+	if breaker := c.GetCircuitBreaker(); breaker != nil {
+		info["circuit_state"] = breaker.State().String()
+		info["consecutive_failures"] = breaker.ConsecutiveFailures()
+		if breaker.State() == CircuitOpen {
+			info["version"] = "unknown"
+			info["status"] = "circuit_open"
+			return info
+		}
+	}
+
+	// 获取版本信息（使用缓存）
+	version, err := c.GetVersionContext(ctx)
 	if err != nil {
 		info["version"] = "unknown"
 		info["status"] = "version_detection_failed"
@@ -1,9 +1,17 @@
 package zabbix
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // GetTriggerEvents 获取触发器事件
 func (c *ZabbixClient) GetTriggerEvents(triggerID string, limit int) ([]map[string]interface{}, error) {
+	return c.GetTriggerEventsContext(context.Background(), triggerID, limit)
+}
+
+// GetTriggerEventsContext 获取触发器事件（支持context取消）
+func (c *ZabbixClient) GetTriggerEventsContext(ctx context.Context, triggerID string, limit int) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":              "extend",
 		"select_acknowledges": "extend",
@@ -15,7 +23,7 @@ func (c *ZabbixClient) GetTriggerEvents(triggerID string, limit int) ([]map[stri
 		"limit":               limit,
 	}
 
-	result, err := c.Call("event.get", params)
+	result, err := c.CallContext(ctx, "event.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -37,17 +45,27 @@ func (c *ZabbixClient) GetTriggerEvents(triggerID string, limit int) ([]map[stri
 
 // AcknowledgeEvent 确认事件
 func (c *ZabbixClient) AcknowledgeEvent(eventID, message string) error {
+	return c.AcknowledgeEventContext(context.Background(), eventID, message)
+}
+
+// AcknowledgeEventContext 确认事件（支持context取消）
+func (c *ZabbixClient) AcknowledgeEventContext(ctx context.Context, eventID, message string) error {
 	params := map[string]interface{}{
 		"eventids": eventID,
 		"message":  message,
 	}
 
-	_, err := c.Call("event.acknowledge", params)
+	_, err := c.CallContext(ctx, "event.acknowledge", params)
 	return err
 }
 
 // GetEvents 获取事件列表
 func (c *ZabbixClient) GetEvents(params map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.GetEventsContext(context.Background(), params)
+}
+
+// GetEventsContext 获取事件列表（支持context取消）
+func (c *ZabbixClient) GetEventsContext(ctx context.Context, params map[string]interface{}) ([]map[string]interface{}, error) {
 	if params == nil {
 		params = map[string]interface{}{
 			"output":    "extend",
@@ -57,7 +75,7 @@ func (c *ZabbixClient) GetEvents(params map[string]interface{}) ([]map[string]in
 		}
 	}
 
-	result, err := c.Call("event.get", params)
+	result, err := c.CallContext(ctx, "event.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +97,11 @@ func (c *ZabbixClient) GetEvents(params map[string]interface{}) ([]map[string]in
 
 // GetEventByID 根据事件ID获取事件信息
 func (c *ZabbixClient) GetEventByID(eventID string) (map[string]interface{}, error) {
+	return c.GetEventByIDContext(context.Background(), eventID)
+}
+
+// GetEventByIDContext 根据事件ID获取事件信息（支持context取消）
+func (c *ZabbixClient) GetEventByIDContext(ctx context.Context, eventID string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":              "extend",
 		"select_acknowledges": "extend",
@@ -86,14 +109,14 @@ func (c *ZabbixClient) GetEventByID(eventID string) (map[string]interface{}, err
 		"eventids":            eventID,
 	}
 
-	result, err := c.Call("event.get", params)
+	result, err := c.CallContext(ctx, "event.get", params)
 	if err != nil {
 		return nil, err
 	}
 
 	events, ok := result.([]interface{})
 	if !ok || len(events) == 0 {
-		return nil, fmt.Errorf("事件不存在")
+		return nil, &NotFoundError{Object: "事件"}
 	}
 
 	if event, ok := events[0].(map[string]interface{}); ok {
@@ -105,17 +128,27 @@ func (c *ZabbixClient) GetEventByID(eventID string) (map[string]interface{}, err
 
 // MassAcknowledgeEvents 批量确认事件
 func (c *ZabbixClient) MassAcknowledgeEvents(eventIDs []string, message string) error {
+	return c.MassAcknowledgeEventsContext(context.Background(), eventIDs, message)
+}
+
+// MassAcknowledgeEventsContext 批量确认事件（支持context取消）
+func (c *ZabbixClient) MassAcknowledgeEventsContext(ctx context.Context, eventIDs []string, message string) error {
 	params := map[string]interface{}{
 		"eventids": eventIDs,
 		"message":  message,
 	}
 
-	_, err := c.Call("event.acknowledge", params)
+	_, err := c.CallContext(ctx, "event.acknowledge", params)
 	return err
 }
 
 // GetProblemEvents 获取问题事件
 func (c *ZabbixClient) GetProblemEvents(params map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.GetProblemEventsContext(context.Background(), params)
+}
+
+// GetProblemEventsContext 获取问题事件（支持context取消）
+func (c *ZabbixClient) GetProblemEventsContext(ctx context.Context, params map[string]interface{}) ([]map[string]interface{}, error) {
 	if params == nil {
 		params = map[string]interface{}{
 			"output":    "extend",
@@ -127,7 +160,7 @@ func (c *ZabbixClient) GetProblemEvents(params map[string]interface{}) ([]map[st
 		}
 	}
 
-	result, err := c.Call("problem.get", params)
+	result, err := c.CallContext(ctx, "problem.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,251 @@
+package zabbix
+
+import (
+	"context"
+	"sync"
+	"time"
+	"zabbix-mcp-go/redact"
+)
+
+// DefaultHealthCheckInterval 后台健康检查的默认间隔
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// DefaultHealthCheckTimeout 单个实例健康检查的默认超时
+const DefaultHealthCheckTimeout = 10 * time.Second
+
+// 不健康实例的重试间隔：从 reconnectBaseDelay 开始每次失败翻倍，最长 reconnectMaxDelay
+const (
+	reconnectBaseDelay = 5 * time.Second
+	reconnectMaxDelay  = 5 * time.Minute
+)
+
+// InstanceState 实例在连接池中的状态
+type InstanceState string
+
+const (
+	// StatePending 尚未连接成功（例如启动时Zabbix不可用），后台按退避间隔重试登录
+	StatePending InstanceState = "pending"
+	// StateHealthy 最近一次登录或健康检查成功
+	StateHealthy InstanceState = "healthy"
+	// StateUnhealthy 曾经连接成功，但最近的健康检查失败，后台按退避间隔重新检查
+	StateUnhealthy InstanceState = "unhealthy"
+)
+
+// HealthStatus 实例的状态和最近一次健康检查的结果
+type HealthStatus struct {
+	State     InstanceState `json:"state"`
+	Healthy   bool          `json:"healthy"`
+	Error     string        `json:"error,omitempty"`
+	Latency   time.Duration `json:"latency_ns"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Since     time.Time     `json:"since"`                // 进入当前状态的时间
+	Failures  int           `json:"failures,omitempty"`   // 连续失败次数
+	NextRetry time.Time     `json:"next_retry,omitempty"` // 不健康时下一次重试的时间
+}
+
+// Ping 检查实例是否可达
+func (c *ZabbixClient) Ping() error {
+	return c.PingContext(context.Background())
+}
+
+// PingContext 调用不需要认证的 apiinfo.version 检查实例是否可达，结果同样计入熔断器
+func (c *ZabbixClient) PingContext(ctx context.Context) error {
+	start := time.Now()
+	ctx, span := c.startSpan(ctx, "zabbix ping")
+
+	err := c.checkCircuit(ctx)
+	if err == nil {
+		_, err = c.callWithAuth(ctx, "apiinfo.version", []interface{}{}, "")
+		c.recordCircuitResult(ctx, err)
+	}
+
+	c.observeCall("apiinfo.version", err, start)
+	endSpan(span, err)
+	return err
+}
+
+// reconnectDelay 计算第failures次失败后的重试间隔
+func reconnectDelay(failures int) time.Duration {
+	delay := reconnectBaseDelay
+	for i := 1; i < failures && delay < reconnectMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > reconnectMaxDelay {
+		delay = reconnectMaxDelay
+	}
+	return delay
+}
+
+// snapshot 复制当前的实例列表，之后的网络调用不持有池的锁
+func (p *ZabbixPool) snapshot() map[string]*ZabbixClient {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	clients := make(map[string]*ZabbixClient, len(p.instances))
+	for name, client := range p.instances {
+		clients[name] = client
+	}
+	return clients
+}
+
+// setHealth 记录实例状态，状态变化时输出日志
+func (p *ZabbixPool) setHealth(name string, status HealthStatus) {
+	p.healthMu.Lock()
+	previous, known := p.health[name]
+	if known && previous.State == status.State {
+		status.Since = previous.Since
+	} else if status.Since.IsZero() {
+		status.Since = time.Now()
+	}
+	p.health[name] = status
+	p.healthMu.Unlock()
+
+	if !known || previous.State == status.State {
+		return
+	}
+	if status.State == StateHealthy {
+		getLogger().Infof("实例 %s 状态变化: %s -> %s", name, previous.State, status.State)
+	} else {
+		getLogger().Warnf("实例 %s 状态变化: %s -> %s: %s", name, previous.State, status.State, status.Error)
+	}
+}
+
+// checkInstance 检查单个实例并返回新的状态：待连接的实例尝试登录，其余实例调用 apiinfo.version
+func (p *ZabbixPool) checkInstance(ctx context.Context, client *ZabbixClient, previous HealthStatus, timeout time.Duration) HealthStatus {
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	if previous.State == StatePending && client.GetAuthToken() == "" {
+		err = client.LoginContext(checkCtx)
+	} else {
+		err = client.PingContext(checkCtx)
+	}
+
+	status := HealthStatus{
+		State:     StateHealthy,
+		Healthy:   err == nil,
+		Latency:   time.Since(start),
+		CheckedAt: time.Now(),
+	}
+	if err == nil {
+		return status
+	}
+
+	status.Error = redact.String(err.Error())
+	status.Failures = previous.Failures + 1
+	status.NextRetry = status.CheckedAt.Add(reconnectDelay(status.Failures))
+	if previous.State == StatePending {
+		status.State = StatePending
+	} else {
+		status.State = StateUnhealthy
+	}
+	return status
+}
+
+// checkHealth 并发检查实例并更新缓存，不持有池的锁。
+// force 为 false 时只检查到期的实例：健康实例每隔 interval 检查一次，不健康实例按退避间隔重试。
+func (p *ZabbixPool) checkHealth(ctx context.Context, force bool, interval, timeout time.Duration) map[string]HealthStatus {
+	clients := p.snapshot()
+	previous := p.CachedHealth()
+	now := time.Now()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]HealthStatus, len(clients))
+	for name, client := range clients {
+		prev, known := previous[name]
+		if !force && known {
+			due := prev.CheckedAt.Add(interval)
+			if !prev.Healthy {
+				due = prev.NextRetry
+			}
+			if now.Before(due) {
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(name string, client *ZabbixClient, prev HealthStatus) {
+			defer wg.Done()
+			status := p.checkInstance(ctx, client, prev, timeout)
+
+			mu.Lock()
+			results[name] = status
+			mu.Unlock()
+		}(name, client, prev)
+	}
+	wg.Wait()
+
+	// 检查期间被移除或替换的实例不再记录
+	current := p.snapshot()
+	for name, status := range results {
+		if current[name] == clients[name] {
+			p.setHealth(name, status)
+		}
+	}
+	return results
+}
+
+// CachedHealth 返回缓存的实例状态，不发起任何请求
+func (p *ZabbixPool) CachedHealth() map[string]HealthStatus {
+	p.healthMu.RLock()
+	defer p.healthMu.RUnlock()
+
+	health := make(map[string]HealthStatus, len(p.health))
+	for name, status := range p.health {
+		health[name] = status
+	}
+	return health
+}
+
+// InstanceHealth 返回单个实例缓存的状态
+func (p *ZabbixPool) InstanceHealth(name string) (HealthStatus, bool) {
+	p.healthMu.RLock()
+	defer p.healthMu.RUnlock()
+
+	status, exists := p.health[name]
+	return status, exists
+}
+
+// forgetHealth 删除实例的状态缓存
+func (p *ZabbixPool) forgetHealth(name string) {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	delete(p.health, name)
+}
+
+// StartHealthMonitor 在后台维护各实例的状态，ctx 取消时停止：
+// 健康实例每隔 interval 检查一次，失败时降级为 unhealthy；
+// pending 和 unhealthy 实例按退避间隔重试，成功后恢复为 healthy。
+// 探针和其他调用方通过 CachedHealth 读取结果。
+func (p *ZabbixPool) StartHealthMonitor(ctx context.Context, interval, timeout time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+
+	// 按较小的粒度唤醒，保证退避重试能按时进行
+	tick := interval
+	if tick > reconnectBaseDelay {
+		tick = reconnectBaseDelay
+	}
+
+	go func() {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+
+		for {
+			p.checkHealth(ctx, false, interval, timeout)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -1,9 +1,17 @@
 package zabbix
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // GetHosts 获取主机列表
 func (c *ZabbixClient) GetHosts(groupID, hostName string) ([]map[string]interface{}, error) {
+	return c.GetHostsContext(context.Background(), groupID, hostName)
+}
+
+// GetHostsContext 获取主机列表（支持context取消）
+func (c *ZabbixClient) GetHostsContext(ctx context.Context, groupID, hostName string) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output": []string{"hostid", "host", "name", "status", "available"},
 		"limit":  1000, // 限制返回数量，避免性能问题
@@ -20,7 +28,7 @@ func (c *ZabbixClient) GetHosts(groupID, hostName string) ([]map[string]interfac
 		params["searchWildcardsEnabled"] = true
 	}
 
-	result, err := c.Call("host.get", params)
+	result, err := c.CallContext(ctx, "host.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +50,11 @@ func (c *ZabbixClient) GetHosts(groupID, hostName string) ([]map[string]interfac
 
 // GetHostsWithPagination 获取主机列表（支持分页）
 func (c *ZabbixClient) GetHostsWithPagination(groupID, hostName string, page, pageSize int) ([]map[string]interface{}, int, error) {
+	return c.GetHostsWithPaginationContext(context.Background(), groupID, hostName, page, pageSize)
+}
+
+// GetHostsWithPaginationContext 获取主机列表（支持分页和context取消）
+func (c *ZabbixClient) GetHostsWithPaginationContext(ctx context.Context, groupID, hostName string, page, pageSize int) ([]map[string]interface{}, int, error) {
 	// 先获取总数
 	countParams := map[string]interface{}{
 		"countOutput": true,
@@ -58,7 +71,7 @@ func (c *ZabbixClient) GetHostsWithPagination(groupID, hostName string, page, pa
 		countParams["searchWildcardsEnabled"] = true
 	}
 
-	result, err := c.Call("host.get", countParams)
+	result, err := c.CallContext(ctx, "host.get", countParams)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -89,7 +102,7 @@ func (c *ZabbixClient) GetHostsWithPagination(groupID, hostName string, page, pa
 		params["searchWildcardsEnabled"] = true
 	}
 
-	result, err = c.Call("host.get", params)
+	result, err = c.CallContext(ctx, "host.get", params)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -111,6 +124,11 @@ func (c *ZabbixClient) GetHostsWithPagination(groupID, hostName string, page, pa
 
 // GetHostByName 根据主机名获取主机信息
 func (c *ZabbixClient) GetHostByName(hostName string) (map[string]interface{}, error) {
+	return c.GetHostByNameContext(context.Background(), hostName)
+}
+
+// GetHostByNameContext 根据主机名获取主机信息（支持context取消）
+func (c *ZabbixClient) GetHostByNameContext(ctx context.Context, hostName string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output": []string{"hostid", "host", "name", "status", "available", "description", "lastaccess"},
 		"filter": map[string]string{
@@ -120,14 +138,14 @@ func (c *ZabbixClient) GetHostByName(hostName string) (map[string]interface{}, e
 		"selectInterfaces": []string{"interfaceid", "ip", "dns", "port", "type", "main", "useip"},
 	}
 
-	result, err := c.Call("host.get", params)
+	result, err := c.CallContext(ctx, "host.get", params)
 	if err != nil {
 		return nil, err
 	}
 
 	hosts, ok := result.([]interface{})
 	if !ok || len(hosts) == 0 {
-		return nil, fmt.Errorf("主机不存在")
+		return nil, &NotFoundError{Object: "主机"}
 	}
 
 	if host, ok := hosts[0].(map[string]interface{}); ok {
@@ -139,6 +157,11 @@ func (c *ZabbixClient) GetHostByName(hostName string) (map[string]interface{}, e
 
 // GetHostByNameLite 轻量级获取主机基本信息（不包含详细的组和接口信息）
 func (c *ZabbixClient) GetHostByNameLite(hostName string) (map[string]interface{}, error) {
+	return c.GetHostByNameLiteContext(context.Background(), hostName)
+}
+
+// GetHostByNameLiteContext 轻量级获取主机基本信息（不包含详细的组和接口信息，支持context取消）
+func (c *ZabbixClient) GetHostByNameLiteContext(ctx context.Context, hostName string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output": []string{"hostid", "host", "name", "status", "available", "description"},
 		"filter": map[string]string{
@@ -148,14 +171,14 @@ func (c *ZabbixClient) GetHostByNameLite(hostName string) (map[string]interface{
 		"selectInterfaces": []string{"interfaceid", "ip", "dns", "port", "type", "main"},
 	}
 
-	result, err := c.Call("host.get", params)
+	result, err := c.CallContext(ctx, "host.get", params)
 	if err != nil {
 		return nil, err
 	}
 
 	hosts, ok := result.([]interface{})
 	if !ok || len(hosts) == 0 {
-		return nil, fmt.Errorf("主机不存在")
+		return nil, &NotFoundError{Object: "主机"}
 	}
 
 	if host, ok := hosts[0].(map[string]interface{}); ok {
@@ -167,6 +190,11 @@ func (c *ZabbixClient) GetHostByNameLite(hostName string) (map[string]interface{
 
 // CreateHost 创建主机
 func (c *ZabbixClient) CreateHost(hostName, groupID, interfaceIP string) (string, error) {
+	return c.CreateHostContext(context.Background(), hostName, groupID, interfaceIP)
+}
+
+// CreateHostContext 创建主机（支持context取消）
+func (c *ZabbixClient) CreateHostContext(ctx context.Context, hostName, groupID, interfaceIP string) (string, error) {
 	params := map[string]interface{}{
 		"host": hostName,
 		"interfaces": []map[string]interface{}{
@@ -186,7 +214,7 @@ func (c *ZabbixClient) CreateHost(hostName, groupID, interfaceIP string) (string
 		},
 	}
 
-	result, err := c.Call("host.create", params)
+	result, err := c.CallContext(ctx, "host.create", params)
 	if err != nil {
 		return "", err
 	}
@@ -206,26 +234,77 @@ func (c *ZabbixClient) CreateHost(hostName, groupID, interfaceIP string) (string
 
 // DeleteHost 删除主机
 func (c *ZabbixClient) DeleteHost(hostID string) error {
+	return c.DeleteHostContext(context.Background(), hostID)
+}
+
+// DeleteHostContext 删除主机（支持context取消）
+func (c *ZabbixClient) DeleteHostContext(ctx context.Context, hostID string) error {
 	params := []string{hostID}
-	_, err := c.Call("host.delete", params)
+	_, err := c.CallContext(ctx, "host.delete", params)
 	return err
 }
 
+// GetHostDeletePreview 获取删除主机时会受影响的内容
+func (c *ZabbixClient) GetHostDeletePreview(hostID string) (map[string]interface{}, error) {
+	return c.GetHostDeletePreviewContext(context.Background(), hostID)
+}
+
+// GetHostDeletePreviewContext 获取删除主机时会受影响的内容：所属主机组、关联的模板，
+// 以及随主机一起删除的监控项、触发器、图形和自动发现规则数量（支持context取消）
+func (c *ZabbixClient) GetHostDeletePreviewContext(ctx context.Context, hostID string) (map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"output":                []string{"hostid", "host", "name", "status"},
+		"hostids":               hostID,
+		"selectGroups":          []string{"groupid", "name"},
+		"selectParentTemplates": []string{"templateid", "name"},
+		"selectItems":           "count",
+		"selectTriggers":        "count",
+		"selectGraphs":          "count",
+		"selectDiscoveries":     "count",
+	}
+
+	result, err := c.CallContext(ctx, "host.get", params)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, ok := result.([]interface{})
+	if !ok || len(hosts) == 0 {
+		return nil, &NotFoundError{Object: "主机"}
+	}
+
+	if host, ok := hosts[0].(map[string]interface{}); ok {
+		return host, nil
+	}
+
+	return nil, fmt.Errorf("响应格式错误")
+}
+
 // UpdateHost 更新主机信息
 func (c *ZabbixClient) UpdateHost(hostID string, params map[string]interface{}) error {
+	return c.UpdateHostContext(context.Background(), hostID, params)
+}
+
+// UpdateHostContext 更新主机信息（支持context取消）
+func (c *ZabbixClient) UpdateHostContext(ctx context.Context, hostID string, params map[string]interface{}) error {
 	params["hostid"] = hostID
-	_, err := c.Call("host.update", params)
+	_, err := c.CallContext(ctx, "host.update", params)
 	return err
 }
 
 // GetHostByID 根据主机ID获取主机信息
 func (c *ZabbixClient) GetHostByID(hostID string) ([]map[string]interface{}, error) {
+	return c.GetHostByIDContext(context.Background(), hostID)
+}
+
+// GetHostByIDContext 根据主机ID获取主机信息（支持context取消）
+func (c *ZabbixClient) GetHostByIDContext(ctx context.Context, hostID string) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":  "extend",
 		"hostids": hostID,
 	}
 
-	result, err := c.Call("host.get", params)
+	result, err := c.CallContext(ctx, "host.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -247,11 +326,16 @@ func (c *ZabbixClient) GetHostByID(hostID string) ([]map[string]interface{}, err
 
 // GetHostGroups 获取主机组列表
 func (c *ZabbixClient) GetHostGroups() ([]map[string]interface{}, error) {
+	return c.GetHostGroupsContext(context.Background())
+}
+
+// GetHostGroupsContext 获取主机组列表（支持context取消）
+func (c *ZabbixClient) GetHostGroupsContext(ctx context.Context) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output": []string{"groupid", "name", "internal"},
 	}
 
-	result, err := c.Call("hostgroup.get", params)
+	result, err := c.CallContext(ctx, "hostgroup.get", params)
 	if err != nil {
 		return nil, err
 	}
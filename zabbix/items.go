@@ -1,6 +1,7 @@
 package zabbix
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -8,6 +9,11 @@ import (
 
 // GetItems 获取主机监控项，支持监控项名称模糊匹配
 func (c *ZabbixClient) GetItems(hostID string, itemNameFilter string) ([]map[string]interface{}, error) {
+	return c.GetItemsContext(context.Background(), hostID, itemNameFilter)
+}
+
+// GetItemsContext 获取主机监控项，支持监控项名称模糊匹配（支持context取消）
+func (c *ZabbixClient) GetItemsContext(ctx context.Context, hostID string, itemNameFilter string) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":             "extend",
 		"hostids":            hostID,
@@ -28,7 +34,7 @@ func (c *ZabbixClient) GetItems(hostID string, itemNameFilter string) ([]map[str
 		params["searchByAny"] = 1            // 匹配任意字段即可
 	}
 
-	result, err := c.Call("item.get", params)
+	result, err := c.CallContext(ctx, "item.get", params)
 	if err != nil {
 		return nil, fmt.Errorf("API调用失败: %v", err)
 	}
@@ -71,6 +77,11 @@ func (c *ZabbixClient) GetItems(hostID string, itemNameFilter string) ([]map[str
 
 // GetItemData 获取监控项数据
 func (c *ZabbixClient) GetItemData(itemID string, history, limit int) ([]map[string]interface{}, error) {
+	return c.GetItemDataContext(context.Background(), itemID, history, limit)
+}
+
+// GetItemDataContext 获取监控项数据（支持context取消）
+func (c *ZabbixClient) GetItemDataContext(ctx context.Context, itemID string, history, limit int) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":    "extend",
 		"history":   history,
@@ -83,7 +94,7 @@ func (c *ZabbixClient) GetItemData(itemID string, history, limit int) ([]map[str
 	// 修复：移除了无意义的条件判断
 	method := "history.get"
 
-	result, err := c.Call(method, params)
+	result, err := c.CallContext(ctx, method, params)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +116,25 @@ func (c *ZabbixClient) GetItemData(itemID string, history, limit int) ([]map[str
 
 // GetItemDataWithTimeRange 获取监控项数据（带时间范围）
 func (c *ZabbixClient) GetItemDataWithTimeRange(itemID string, history int, timeFrom, timeTill string) ([]map[string]interface{}, error) {
+	return c.GetItemDataWithTimeRangeContext(context.Background(), itemID, history, timeFrom, timeTill)
+}
+
+// GetItemDataWithTimeRangeContext 获取监控项数据（带时间范围，支持context取消）
+func (c *ZabbixClient) GetItemDataWithTimeRangeContext(ctx context.Context, itemID string, history int, timeFrom, timeTill string) ([]map[string]interface{}, error) {
+	params := c.historyTimeRangeParams(itemID, history, timeFrom, timeTill)
+
+	method := "history.get"
+
+	result, err := c.CallContext(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHistoryData(result)
+}
+
+// historyTimeRangeParams 构建带时间范围的 history.get 参数
+func (c *ZabbixClient) historyTimeRangeParams(itemID string, history int, timeFrom, timeTill string) map[string]interface{} {
 	params := map[string]interface{}{
 		"output":    "extend",
 		"history":   history,
@@ -157,13 +187,11 @@ func (c *ZabbixClient) GetItemDataWithTimeRange(itemID string, history int, time
 		params["time_till"] = unixTill
 	}
 
-	method := "history.get"
-
-	result, err := c.Call(method, params)
-	if err != nil {
-		return nil, err
-	}
+	return params
+}
 
+// parseHistoryData 解析 history.get 的返回结果
+func parseHistoryData(result interface{}) ([]map[string]interface{}, error) {
 	data, ok := result.([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("响应格式错误")
@@ -180,6 +208,11 @@ func (c *ZabbixClient) GetItemDataWithTimeRange(itemID string, history int, time
 
 // CreateItem 创建监控项
 func (c *ZabbixClient) CreateItem(hostID, name, key, type_, valueType, delay string) (string, error) {
+	return c.CreateItemContext(context.Background(), hostID, name, key, type_, valueType, delay)
+}
+
+// CreateItemContext 创建监控项（支持context取消）
+func (c *ZabbixClient) CreateItemContext(ctx context.Context, hostID, name, key, type_, valueType, delay string) (string, error) {
 	if type_ == "" {
 		type_ = "0" // Zabbix agent
 	}
@@ -200,7 +233,7 @@ func (c *ZabbixClient) CreateItem(hostID, name, key, type_, valueType, delay str
 		"interfaceid": "$1", // 使用默认接口
 	}
 
-	result, err := c.Call("item.create", params)
+	result, err := c.CallContext(ctx, "item.create", params)
 	if err != nil {
 		return "", err
 	}
@@ -220,20 +253,35 @@ func (c *ZabbixClient) CreateItem(hostID, name, key, type_, valueType, delay str
 
 // UpdateItem 更新监控项
 func (c *ZabbixClient) UpdateItem(itemID string, params map[string]interface{}) error {
+	return c.UpdateItemContext(context.Background(), itemID, params)
+}
+
+// UpdateItemContext 更新监控项（支持context取消）
+func (c *ZabbixClient) UpdateItemContext(ctx context.Context, itemID string, params map[string]interface{}) error {
 	params["itemid"] = itemID
-	_, err := c.Call("item.update", params)
+	_, err := c.CallContext(ctx, "item.update", params)
 	return err
 }
 
 // DeleteItem 删除监控项
 func (c *ZabbixClient) DeleteItem(itemID string) error {
+	return c.DeleteItemContext(context.Background(), itemID)
+}
+
+// DeleteItemContext 删除监控项（支持context取消）
+func (c *ZabbixClient) DeleteItemContext(ctx context.Context, itemID string) error {
 	params := []string{itemID}
-	_, err := c.Call("item.delete", params)
+	_, err := c.CallContext(ctx, "item.delete", params)
 	return err
 }
 
 // GetItemByKey 根据监控项键获取监控项信息
 func (c *ZabbixClient) GetItemByKey(hostID, key string) (map[string]interface{}, error) {
+	return c.GetItemByKeyContext(context.Background(), hostID, key)
+}
+
+// GetItemByKeyContext 根据监控项键获取监控项信息（支持context取消）
+func (c *ZabbixClient) GetItemByKeyContext(ctx context.Context, hostID, key string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":  "extend",
 		"hostids": hostID,
@@ -242,14 +290,14 @@ func (c *ZabbixClient) GetItemByKey(hostID, key string) (map[string]interface{},
 		},
 	}
 
-	result, err := c.Call("item.get", params)
+	result, err := c.CallContext(ctx, "item.get", params)
 	if err != nil {
 		return nil, err
 	}
 
 	items, ok := result.([]interface{})
 	if !ok || len(items) == 0 {
-		return nil, fmt.Errorf("监控项不存在")
+		return nil, &NotFoundError{Object: "监控项"}
 	}
 
 	if item, ok := items[0].(map[string]interface{}); ok {
@@ -261,19 +309,67 @@ func (c *ZabbixClient) GetItemByKey(hostID, key string) (map[string]interface{},
 
 // GetItemInfo 根据监控项ID获取监控项详细信息
 func (c *ZabbixClient) GetItemInfo(itemID string) (map[string]interface{}, error) {
-	params := map[string]interface{}{
-		"output":  "extend",
-		"itemids": itemID,
-	}
+	return c.GetItemInfoContext(context.Background(), itemID)
+}
 
-	result, err := c.Call("item.get", params)
+// GetItemInfoContext 根据监控项ID获取监控项详细信息（支持context取消）
+func (c *ZabbixClient) GetItemInfoContext(ctx context.Context, itemID string) (map[string]interface{}, error) {
+	result, err := c.CallContext(ctx, "item.get", itemInfoParams(itemID))
 	if err != nil {
 		return nil, err
 	}
 
+	return parseItemInfo(result)
+}
+
+// GetItemWithHistory 在一次批量请求中获取监控项信息和带时间范围的历史数据
+func (c *ZabbixClient) GetItemWithHistory(itemID string, history int, timeFrom, timeTill string) (map[string]interface{}, []map[string]interface{}, error) {
+	return c.GetItemWithHistoryContext(context.Background(), itemID, history, timeFrom, timeTill)
+}
+
+// GetItemWithHistoryContext 在一次批量请求中获取监控项信息和带时间范围的历史数据（支持context取消）
+func (c *ZabbixClient) GetItemWithHistoryContext(ctx context.Context, itemID string, history int, timeFrom, timeTill string) (map[string]interface{}, []map[string]interface{}, error) {
+	batch := c.NewBatch()
+	itemIdx := batch.Add("item.get", itemInfoParams(itemID))
+	historyIdx := batch.Add("history.get", c.historyTimeRangeParams(itemID, history, timeFrom, timeTill))
+
+	results, err := batch.Execute(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if results[itemIdx].Error != nil {
+		return nil, nil, fmt.Errorf("获取监控项信息失败: %w", results[itemIdx].Error)
+	}
+	item, err := parseItemInfo(results[itemIdx].Result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if results[historyIdx].Error != nil {
+		return nil, nil, fmt.Errorf("获取监控项历史数据失败: %w", results[historyIdx].Error)
+	}
+	historyData, err := parseHistoryData(results[historyIdx].Result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return item, historyData, nil
+}
+
+// itemInfoParams 构建按ID查询监控项的 item.get 参数
+func itemInfoParams(itemID string) map[string]interface{} {
+	return map[string]interface{}{
+		"output":  "extend",
+		"itemids": itemID,
+	}
+}
+
+// parseItemInfo 解析 item.get 的返回结果，取第一个监控项
+func parseItemInfo(result interface{}) (map[string]interface{}, error) {
 	items, ok := result.([]interface{})
 	if !ok || len(items) == 0 {
-		return nil, fmt.Errorf("监控项不存在")
+		return nil, &NotFoundError{Object: "监控项"}
 	}
 
 	if item, ok := items[0].(map[string]interface{}); ok {
@@ -0,0 +1,42 @@
+package zabbix
+
+import "sync"
+
+// Logger zabbix包输出日志使用的接口，*zap.SugaredLogger 满足该接口。
+// 包内不直接写stdout，stdio传输方式下stdout只能用于MCP协议。
+type Logger interface {
+	Debugf(template string, args ...interface{})
+	Infof(template string, args ...interface{})
+	Warnf(template string, args ...interface{})
+	Errorf(template string, args ...interface{})
+}
+
+var (
+	pkgLogger Logger = nopLogger{}
+	loggerMu  sync.RWMutex
+)
+
+// SetLogger 设置zabbix包使用的日志记录器，传入nil时丢弃所有日志
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	pkgLogger = l
+}
+
+// getLogger 返回当前的日志记录器
+func getLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return pkgLogger
+}
+
+// nopLogger 未设置日志记录器时使用，丢弃所有日志
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Infof(string, ...interface{})  {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+func (nopLogger) Errorf(string, ...interface{}) {}
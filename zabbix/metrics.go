@@ -0,0 +1,43 @@
+package zabbix
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"zabbix-mcp-go/metrics"
+)
+
+// errorCode 将API调用错误归类为指标标签：ok、JSON-RPC错误码、http_<状态码>或其他类别
+func errorCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return strconv.Itoa(rpcErr.Code)
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return "http_" + strconv.Itoa(statusErr.StatusCode)
+	}
+
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrPHPTimeout):
+		return "php_timeout"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+// observeCall 记录一次API调用的次数和耗时
+func (c *ZabbixClient) observeCall(method string, err error, start time.Time) {
+	metrics.ObserveAPICall(c.Name(), method, errorCode(err), time.Since(start))
+}
@@ -1,8 +1,12 @@
 package zabbix
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
+	"zabbix-mcp-go/redact"
 )
 
 // ZabbixPool Zabbix客户端连接池
@@ -10,12 +14,17 @@ type ZabbixPool struct {
 	instances       map[string]*ZabbixClient
 	defaultInstance string
 	mu              sync.RWMutex
+
+	// health 后台健康检查缓存的结果，使用独立的锁，读取时不阻塞实例的增删
+	health   map[string]HealthStatus
+	healthMu sync.RWMutex
 }
 
 // NewZabbixPool 创建新的Zabbix连接池
 func NewZabbixPool() *ZabbixPool {
 	return &ZabbixPool{
 		instances: make(map[string]*ZabbixClient),
+		health:    make(map[string]HealthStatus),
 	}
 }
 
@@ -28,12 +37,20 @@ func (p *ZabbixPool) AddInstance(name string, client *ZabbixClient) error {
 		return fmt.Errorf("实例 %s 已存在", name)
 	}
 
+	client.SetName(name)
+
 	// 尝试登录验证连接
 	if err := client.Login(); err != nil {
 		return fmt.Errorf("连接实例 %s 失败: %w", name, err)
 	}
 
+	// 未单独配置熔断器的实例使用默认参数
+	if client.GetCircuitBreaker() == nil {
+		client.SetCircuitBreaker(NewCircuitBreaker(DefaultFailureThreshold, DefaultOpenTimeout))
+	}
+
 	p.instances[name] = client
+	p.setHealth(name, HealthStatus{State: StateHealthy, Healthy: true, CheckedAt: time.Now()})
 
 	// 如果这是第一个实例，设为默认
 	if p.defaultInstance == "" {
@@ -43,6 +60,33 @@ func (p *ZabbixPool) AddInstance(name string, client *ZabbixClient) error {
 	return nil
 }
 
+// AddPendingInstance 添加连接失败的实例，状态为 pending，由 StartHealthMonitor 在后台按退避间隔重试登录。
+// 在此期间调用该实例会在请求时再次尝试登录。
+func (p *ZabbixPool) AddPendingInstance(name string, client *ZabbixClient, cause error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.instances[name]; exists {
+		return fmt.Errorf("实例 %s 已存在", name)
+	}
+
+	client.SetName(name)
+	if client.GetCircuitBreaker() == nil {
+		client.SetCircuitBreaker(NewCircuitBreaker(DefaultFailureThreshold, DefaultOpenTimeout))
+	}
+
+	p.instances[name] = client
+
+	now := time.Now()
+	status := HealthStatus{State: StatePending, CheckedAt: now, Failures: 1, NextRetry: now.Add(reconnectDelay(1))}
+	if cause != nil {
+		status.Error = redact.String(cause.Error())
+	}
+	p.setHealth(name, status)
+
+	return nil
+}
+
 // RemoveInstance 从池中移除实例
 func (p *ZabbixPool) RemoveInstance(name string) error {
 	p.mu.Lock()
@@ -56,10 +100,12 @@ func (p *ZabbixPool) RemoveInstance(name string) error {
 	// 登出并关闭连接
 	if err := client.Logout(); err != nil {
 		// 记录错误但不阻止移除
-		fmt.Printf("警告: 登出实例 %s 失败: %v\n", name, err)
+		getLogger().Warnf("登出实例 %s 失败: %v", name, err)
 	}
+	client.SetExtraHeaders(nil)
 
 	delete(p.instances, name)
+	p.forgetHealth(name)
 
 	// 如果移除的是默认实例，重新选择默认实例
 	if p.defaultInstance == name {
@@ -73,6 +119,47 @@ func (p *ZabbixPool) RemoveInstance(name string) error {
 	return nil
 }
 
+// ReplaceInstance 用新客户端替换已有实例（例如连接参数变化时），默认实例设置保持不变。
+// 新客户端登录成功后才会替换，旧客户端随后登出。
+func (p *ZabbixPool) ReplaceInstance(name string, client *ZabbixClient) error {
+	client.SetName(name)
+
+	// 登录可能较慢，不持有锁
+	if err := client.Login(); err != nil {
+		return fmt.Errorf("连接实例 %s 失败: %w", name, err)
+	}
+
+	if client.GetCircuitBreaker() == nil {
+		client.SetCircuitBreaker(NewCircuitBreaker(DefaultFailureThreshold, DefaultOpenTimeout))
+	}
+
+	p.mu.Lock()
+	old, exists := p.instances[name]
+	if !exists {
+		p.mu.Unlock()
+		return fmt.Errorf("实例 %s 不存在", name)
+	}
+	p.instances[name] = client
+	p.mu.Unlock()
+	p.forgetHealth(name)
+	p.setHealth(name, HealthStatus{State: StateHealthy, Healthy: true, CheckedAt: time.Now()})
+
+	if err := old.Logout(); err != nil {
+		getLogger().Warnf("登出实例 %s 失败: %v", name, err)
+	}
+	// 旧客户端不再使用，移除其额外头部在日志脱敏中的登记
+	old.SetExtraHeaders(nil)
+
+	return nil
+}
+
+// GetZabbixClient 获取指定实例的客户端（类型化版本），实例不存在时返回nil
+func (p *ZabbixPool) GetZabbixClient(name string) *ZabbixClient {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.instances[name]
+}
+
 // GetClient 获取指定实例的客户端
 func (p *ZabbixPool) GetClient(instanceName string) interface{} {
 	p.mu.RLock()
@@ -123,13 +210,29 @@ func (p *ZabbixPool) ListInstances() []map[string]interface{} {
 	for name, client := range p.instances {
 		instance := map[string]interface{}{
 			"name":      name,
-			"url":       client.URL,
-			"user":      client.User,
+			"url":       redact.String(client.URL),
 			"default":   name == p.defaultInstance,
-			"connected": client.AuthToken != "",
+			"connected": client.GetAuthToken() != "",
+		}
+		if breaker := client.GetCircuitBreaker(); breaker != nil {
+			instance["circuit_state"] = breaker.State().String()
+			instance["consecutive_failures"] = breaker.ConsecutiveFailures()
+		}
+		if status, exists := p.InstanceHealth(name); exists {
+			instance["state"] = status.State
+			instance["state_since"] = status.Since
+			if status.Error != "" {
+				instance["last_error"] = status.Error
+			}
+			if !status.NextRetry.IsZero() {
+				instance["next_retry"] = status.NextRetry
+			}
 		}
 		instances = append(instances, instance)
 	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i]["name"].(string) < instances[j]["name"].(string)
+	})
 
 	return instances
 }
@@ -155,16 +258,12 @@ func (p *ZabbixPool) GetDefaultInstanceName() string {
 	return p.defaultInstance
 }
 
-// HealthCheck 检查所有实例的健康状态
+// HealthCheck 立即检查所有实例的健康状态并更新缓存。
+// 并发检查且不持有池的锁，需要频繁读取时使用 CachedHealth。
 func (p *ZabbixPool) HealthCheck() map[string]bool {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
 	health := make(map[string]bool)
-	for name, client := range p.instances {
-		// 尝试调用一个简单的API来检查连接状态
-		_, err := client.Call("apiinfo.version", nil)
-		health[name] = err == nil
+	for name, status := range p.checkHealth(context.Background(), true, 0, DefaultHealthCheckTimeout) {
+		health[name] = status.Healthy
 	}
 
 	return health
@@ -200,22 +299,28 @@ func (p *ZabbixPool) Clear() {
 	// 登出所有实例
 	for name, client := range p.instances {
 		if err := client.Logout(); err != nil {
-			fmt.Printf("警告: 登出实例 %s 失败: %v\n", name, err)
+			getLogger().Warnf("登出实例 %s 失败: %v", name, err)
 		}
 	}
 
 	// 清空映射
 	p.instances = make(map[string]*ZabbixClient)
 	p.defaultInstance = ""
+
+	p.healthMu.Lock()
+	p.health = make(map[string]HealthStatus)
+	p.healthMu.Unlock()
 }
 
 // InstanceStats 实例统计信息
 type InstanceStats struct {
-	Name      string
-	URL       string
-	Connected bool
-	AuthToken bool
-	IsDefault bool
+	Name                string
+	URL                 string
+	Connected           bool
+	AuthToken           bool
+	IsDefault           bool
+	CircuitState        string
+	ConsecutiveFailures int
 }
 
 // GetInstanceStats 获取实例统计信息
@@ -225,13 +330,18 @@ func (p *ZabbixPool) GetInstanceStats() []InstanceStats {
 
 	var stats []InstanceStats
 	for name, client := range p.instances {
+		connected := client.GetAuthToken() != ""
 		stat := InstanceStats{
 			Name:      name,
 			URL:       client.URL,
-			Connected: client.AuthToken != "",
-			AuthToken: client.AuthToken != "",
+			Connected: connected,
+			AuthToken: connected,
 			IsDefault: name == p.defaultInstance,
 		}
+		if breaker := client.GetCircuitBreaker(); breaker != nil {
+			stat.CircuitState = breaker.State().String()
+			stat.ConsecutiveFailures = breaker.ConsecutiveFailures()
+		}
 		stats = append(stats, stat)
 	}
 
@@ -247,6 +357,11 @@ type MultiInstanceQuery struct {
 
 // QueryAllInstances 查询所有实例
 func (p *ZabbixPool) QueryAllInstances(method string, params interface{}) []MultiInstanceQuery {
+	return p.QueryAllInstancesContext(context.Background(), method, params)
+}
+
+// QueryAllInstancesContext 查询所有实例（支持context取消）
+func (p *ZabbixPool) QueryAllInstancesContext(ctx context.Context, method string, params interface{}) []MultiInstanceQuery {
 	p.mu.RLock()
 	instances := make(map[string]*ZabbixClient)
 	for name, client := range p.instances {
@@ -263,7 +378,7 @@ func (p *ZabbixPool) QueryAllInstances(method string, params interface{}) []Mult
 		go func(instanceName string, zabbixClient *ZabbixClient) {
 			defer wg.Done()
 
-			result, err := zabbixClient.Call(method, params)
+			result, err := zabbixClient.CallContext(ctx, method, params)
 			resultChan <- MultiInstanceQuery{
 				InstanceName: instanceName,
 				Result:       result,
@@ -284,6 +399,11 @@ func (p *ZabbixPool) QueryAllInstances(method string, params interface{}) []Mult
 
 // QueryHealthyInstances 只查询健康的实例
 func (p *ZabbixPool) QueryHealthyInstances(method string, params interface{}) []MultiInstanceQuery {
+	return p.QueryHealthyInstancesContext(context.Background(), method, params)
+}
+
+// QueryHealthyInstancesContext 只查询健康的实例（支持context取消）
+func (p *ZabbixPool) QueryHealthyInstancesContext(ctx context.Context, method string, params interface{}) []MultiInstanceQuery {
 	healthyInstances := p.GetHealthyInstances()
 
 	p.mu.RLock()
@@ -302,7 +422,7 @@ func (p *ZabbixPool) QueryHealthyInstances(method string, params interface{}) []
 		go func(instanceName string, zabbixClient *ZabbixClient) {
 			defer wg.Done()
 
-			result, err := zabbixClient.Call(method, params)
+			result, err := zabbixClient.CallContext(ctx, method, params)
 			resultChan <- MultiInstanceQuery{
 				InstanceName: instanceName,
 				Result:       result,
@@ -323,22 +443,33 @@ func (p *ZabbixPool) QueryHealthyInstances(method string, params interface{}) []
 
 // GetAllInstancesInfo 获取所有实例的详细信息
 func (p *ZabbixPool) GetAllInstancesInfo() map[string]interface{} {
+	return p.GetAllInstancesInfoContext(context.Background())
+}
+
+// GetAllInstancesInfoContext 获取所有实例的详细信息（支持context取消）。
+// 获取实例信息需要调用Zabbix API，先复制实例列表再释放锁，避免慢实例阻塞连接池的其他操作
+func (p *ZabbixPool) GetAllInstancesInfoContext(ctx context.Context) map[string]interface{} {
 	p.mu.RLock()
-	defer p.mu.RUnlock()
+	clients := make(map[string]*ZabbixClient, len(p.instances))
+	for name, client := range p.instances {
+		clients[name] = client
+	}
+	defaultInstance := p.defaultInstance
+	p.mu.RUnlock()
 
 	result := make(map[string]interface{})
 	instances := make([]map[string]interface{}, 0)
 
-	for name, client := range p.instances {
-		info := client.GetInstanceInfo()
+	for name, client := range clients {
+		info := client.GetInstanceInfoContext(ctx)
 		info["name"] = name
-		info["is_default"] = (name == p.defaultInstance)
+		info["is_default"] = (name == defaultInstance)
 		instances = append(instances, info)
 	}
 
 	result["instances"] = instances
 	result["total_count"] = len(instances)
-	result["default_instance"] = p.defaultInstance
+	result["default_instance"] = defaultInstance
 
 	return result
 }
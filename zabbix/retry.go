@@ -0,0 +1,157 @@
+package zabbix
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"zabbix-mcp-go/redact"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryPolicy 重试策略，只应用于幂等的只读方法
+type RetryPolicy struct {
+	// MaxAttempts 最大尝试次数（包含第一次），小于等于1表示不重试
+	MaxAttempts int
+	// BaseDelay 第一次重试前的基础等待时间，之后按指数增长
+	BaseDelay time.Duration
+	// MaxDelay 单次等待时间上限
+	MaxDelay time.Duration
+	// Retryable 判断错误是否可重试，为nil时使用 IsRetryableError
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy 默认重试策略：最多3次，500ms起步，最长等待5s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Retryable:   IsRetryableError,
+	}
+}
+
+// SetRetryPolicy 设置重试策略
+func (c *ZabbixClient) SetRetryPolicy(policy RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryableError
+	}
+	c.retryPolicy = policy
+}
+
+// GetRetryPolicy 获取当前重试策略
+func (c *ZabbixClient) GetRetryPolicy() RetryPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retryPolicy
+}
+
+// IsIdempotentMethod 判断Zabbix API方法是否为幂等的只读方法，只有这些方法才允许重试
+func IsIdempotentMethod(method string) bool {
+	return strings.HasSuffix(method, ".get") || method == "apiinfo.version"
+}
+
+// IsRetryableError 默认的可重试错误判断：网络错误、HTTP 502/503/504 和PHP执行超时
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// 调用方主动取消或超时不重试
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, ErrPHPTimeout) {
+		return true
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	// Zabbix API业务错误（参数错误、权限不足等）重试也不会成功
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// callWithRetry 按重试策略调用API，非幂等方法只调用一次
+func (c *ZabbixClient) callWithRetry(ctx context.Context, method string, params interface{}, auth string) (interface{}, error) {
+	var result interface{}
+	err := c.withRetry(ctx, IsIdempotentMethod(method), func() error {
+		var err error
+		result, err = c.call(ctx, method, params, auth)
+		return err
+	})
+	return result, err
+}
+
+// withRetry 按重试策略执行fn；idempotent为false时只执行一次
+func (c *ZabbixClient) withRetry(ctx context.Context, idempotent bool, fn func() error) error {
+	// 最终的尝试次数记录在当前span上，每次重试记录一个事件
+	span := trace.SpanFromContext(ctx)
+
+	policy := c.GetRetryPolicy()
+	if policy.MaxAttempts <= 1 || !idempotent {
+		span.SetAttributes(attribute.Int("zabbix.attempt", 1))
+		return fn()
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		span.SetAttributes(attribute.Int("zabbix.attempt", attempt))
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt == policy.MaxAttempts || !policy.Retryable(err) {
+			break
+		}
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("zabbix.attempt", attempt),
+			attribute.String("error", redact.String(err.Error())),
+		))
+
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+
+	return lastErr
+}
+
+// backoff 计算第attempt次失败后的等待时间（指数退避 + 随机抖动）
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// 在 [delay/2, delay] 范围内随机，避免多个请求同时重试
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
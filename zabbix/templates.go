@@ -1,15 +1,23 @@
 package zabbix
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // GetTemplates 获取模板列表
 func (c *ZabbixClient) GetTemplates() ([]map[string]interface{}, error) {
+	return c.GetTemplatesContext(context.Background())
+}
+
+// GetTemplatesContext 获取模板列表（支持context取消）
+func (c *ZabbixClient) GetTemplatesContext(ctx context.Context) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":       []string{"templateid", "host", "name", "description"},
 		"selectGroups": "extend",
 	}
 
-	result, err := c.Call("template.get", params)
+	result, err := c.CallContext(ctx, "template.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,11 @@ func (c *ZabbixClient) GetTemplates() ([]map[string]interface{}, error) {
 
 // GetTemplateByID 根据模板ID获取模板信息
 func (c *ZabbixClient) GetTemplateByID(templateID string) (map[string]interface{}, error) {
+	return c.GetTemplateByIDContext(context.Background(), templateID)
+}
+
+// GetTemplateByIDContext 根据模板ID获取模板信息（支持context取消）
+func (c *ZabbixClient) GetTemplateByIDContext(ctx context.Context, templateID string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":             "extend",
 		"selectGroups":       "extend",
@@ -42,14 +55,14 @@ func (c *ZabbixClient) GetTemplateByID(templateID string) (map[string]interface{
 		"templateids":        templateID,
 	}
 
-	result, err := c.Call("template.get", params)
+	result, err := c.CallContext(ctx, "template.get", params)
 	if err != nil {
 		return nil, err
 	}
 
 	templates, ok := result.([]interface{})
 	if !ok || len(templates) == 0 {
-		return nil, fmt.Errorf("模板不存在")
+		return nil, &NotFoundError{Object: "模板"}
 	}
 
 	if template, ok := templates[0].(map[string]interface{}); ok {
@@ -61,6 +74,11 @@ func (c *ZabbixClient) GetTemplateByID(templateID string) (map[string]interface{
 
 // LinkTemplate 关联模板到主机
 func (c *ZabbixClient) LinkTemplate(hostID, templateID string) error {
+	return c.LinkTemplateContext(context.Background(), hostID, templateID)
+}
+
+// LinkTemplateContext 关联模板到主机（支持context取消）
+func (c *ZabbixClient) LinkTemplateContext(ctx context.Context, hostID, templateID string) error {
 	params := map[string]interface{}{
 		"hosts": []map[string]string{
 			{"hostid": hostID},
@@ -70,23 +88,33 @@ func (c *ZabbixClient) LinkTemplate(hostID, templateID string) error {
 		},
 	}
 
-	_, err := c.Call("template.massadd", params)
+	_, err := c.CallContext(ctx, "template.massadd", params)
 	return err
 }
 
 // UnlinkTemplate 从主机移除模板
 func (c *ZabbixClient) UnlinkTemplate(hostID, templateID string) error {
+	return c.UnlinkTemplateContext(context.Background(), hostID, templateID)
+}
+
+// UnlinkTemplateContext 从主机移除模板（支持context取消）
+func (c *ZabbixClient) UnlinkTemplateContext(ctx context.Context, hostID, templateID string) error {
 	params := map[string]interface{}{
 		"hostids":           hostID,
 		"templateids_clear": templateID,
 	}
 
-	_, err := c.Call("host.massremove", params)
+	_, err := c.CallContext(ctx, "host.massremove", params)
 	return err
 }
 
 // MassLinkTemplates 批量关联模板到主机
 func (c *ZabbixClient) MassLinkTemplates(hostIDs, templateIDs []string) error {
+	return c.MassLinkTemplatesContext(context.Background(), hostIDs, templateIDs)
+}
+
+// MassLinkTemplatesContext 批量关联模板到主机（支持context取消）
+func (c *ZabbixClient) MassLinkTemplatesContext(ctx context.Context, hostIDs, templateIDs []string) error {
 	hosts := make([]map[string]string, len(hostIDs))
 	for i, hostID := range hostIDs {
 		hosts[i] = map[string]string{"hostid": hostID}
@@ -102,29 +130,39 @@ func (c *ZabbixClient) MassLinkTemplates(hostIDs, templateIDs []string) error {
 		"templates": templates,
 	}
 
-	_, err := c.Call("template.massadd", params)
+	_, err := c.CallContext(ctx, "template.massadd", params)
 	return err
 }
 
 // MassUnlinkTemplates 从主机批量移除模板
 func (c *ZabbixClient) MassUnlinkTemplates(hostIDs, templateIDs []string) error {
+	return c.MassUnlinkTemplatesContext(context.Background(), hostIDs, templateIDs)
+}
+
+// MassUnlinkTemplatesContext 从主机批量移除模板（支持context取消）
+func (c *ZabbixClient) MassUnlinkTemplatesContext(ctx context.Context, hostIDs, templateIDs []string) error {
 	params := map[string]interface{}{
 		"hostids":           hostIDs,
 		"templateids_clear": templateIDs,
 	}
 
-	_, err := c.Call("host.massremove", params)
+	_, err := c.CallContext(ctx, "host.massremove", params)
 	return err
 }
 
 // GetTemplatesByHost 获取主机关联的模板
 func (c *ZabbixClient) GetTemplatesByHost(hostID string) ([]map[string]interface{}, error) {
+	return c.GetTemplatesByHostContext(context.Background(), hostID)
+}
+
+// GetTemplatesByHostContext 获取主机关联的模板（支持context取消）
+func (c *ZabbixClient) GetTemplatesByHostContext(ctx context.Context, hostID string) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":  []string{"templateid", "host", "name", "description"},
 		"hostids": hostID,
 	}
 
-	result, err := c.Call("template.get", params)
+	result, err := c.CallContext(ctx, "template.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -146,20 +184,81 @@ func (c *ZabbixClient) GetTemplatesByHost(hostID string) ([]map[string]interface
 
 // LinkTemplates 关联模板到主机（单主机版本）
 func (c *ZabbixClient) LinkTemplates(hostID string, templateIDs []string) error {
-	return c.MassLinkTemplates([]string{hostID}, templateIDs)
+	return c.LinkTemplatesContext(context.Background(), hostID, templateIDs)
+}
+
+// LinkTemplatesContext 关联模板到主机（单主机版本，支持context取消）
+func (c *ZabbixClient) LinkTemplatesContext(ctx context.Context, hostID string, templateIDs []string) error {
+	return c.MassLinkTemplatesContext(ctx, []string{hostID}, templateIDs)
 }
 
 // UnlinkTemplates 从主机移除模板（单主机版本）
 func (c *ZabbixClient) UnlinkTemplates(hostID string, templateIDs []string, clear bool) error {
+	return c.UnlinkTemplatesContext(context.Background(), hostID, templateIDs, clear)
+}
+
+// UnlinkTemplatesContext 从主机移除模板（单主机版本，支持context取消）
+func (c *ZabbixClient) UnlinkTemplatesContext(ctx context.Context, hostID string, templateIDs []string, clear bool) error {
 	if clear {
-		return c.MassUnlinkTemplates([]string{hostID}, templateIDs)
+		return c.MassUnlinkTemplatesContext(ctx, []string{hostID}, templateIDs)
 	}
-	// 如果不清除，使用host.update方法
+	// 不清除时只取消关联，从模板继承的监控项和触发器保留在主机上。
+	// host.update 的 templates_clear 会同时删除继承的实体，不能用于这种情况
 	params := map[string]interface{}{
-		"hostid":          hostID,
-		"templates_clear": templateIDs,
+		"hostids":     []string{hostID},
+		"templateids": templateIDs,
 	}
 
-	_, err := c.Call("host.update", params)
+	_, err := c.CallContext(ctx, "host.massremove", params)
 	return err
 }
+
+// GetTemplateUnlinkPreview 获取从主机移除并清除模板时会受影响的内容
+func (c *ZabbixClient) GetTemplateUnlinkPreview(hostID string, templateIDs []string) ([]map[string]interface{}, error) {
+	return c.GetTemplateUnlinkPreviewContext(context.Background(), hostID, templateIDs)
+}
+
+// GetTemplateUnlinkPreviewContext 获取从主机移除并清除模板时会受影响的内容：每个模板的名称，
+// 以及其中会从主机上删除的监控项和触发器数量。模板没有关联到该主机时返回错误（支持context取消）
+func (c *ZabbixClient) GetTemplateUnlinkPreviewContext(ctx context.Context, hostID string, templateIDs []string) ([]map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"output":         []string{"templateid", "host", "name"},
+		"templateids":    templateIDs,
+		"hostids":        hostID,
+		"selectItems":    "count",
+		"selectTriggers": "count",
+	}
+
+	result, err := c.CallContext(ctx, "template.get", params)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("响应格式错误")
+	}
+
+	linked := make(map[string]bool)
+	var templateList []map[string]interface{}
+	for _, t := range templates {
+		if template, ok := t.(map[string]interface{}); ok {
+			templateList = append(templateList, template)
+			if id, ok := template["templateid"].(string); ok {
+				linked[id] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, id := range templateIDs {
+		if !linked[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("模板未关联到该主机: %v", missing)
+	}
+
+	return templateList, nil
+}
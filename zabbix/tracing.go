@@ -0,0 +1,32 @@
+package zabbix
+
+import (
+	"context"
+	"errors"
+	"zabbix-mcp-go/redact"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer 未配置链路追踪时为空实现，开销可以忽略
+var tracer = otel.Tracer("zabbix-mcp-go/zabbix")
+
+// startSpan 创建API调用的子span，父span通常是MCP工具调用
+func (c *ZabbixClient) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("zabbix.instance", c.Name()))
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan 记录错误类别并结束span，错误信息与日志一样经过脱敏
+func endSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.String("zabbix.code", errorCode(err)))
+	if err != nil {
+		message := redact.String(err.Error())
+		span.RecordError(errors.New(message))
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
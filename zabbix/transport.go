@@ -0,0 +1,136 @@
+package zabbix
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"zabbix-mcp-go/redact"
+)
+
+// TLSOptions 实例级TLS配置
+type TLSOptions struct {
+	CAFile             string // 自定义CA证书（PEM），用于校验企业内部CA签发的服务器证书
+	CertFile           string // 客户端证书（PEM），用于mTLS
+	KeyFile            string // 客户端私钥（PEM），用于mTLS
+	ServerName         string // 覆盖用于证书校验的服务器名称
+	MinVersion         string // 最低TLS版本："1.0"、"1.1"、"1.2"、"1.3"，默认"1.2"
+	InsecureSkipVerify bool   // 跳过服务器证书校验，仅用于测试环境
+}
+
+// tlsVersions 支持的最低TLS版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// BuildTLSConfig 根据配置构建 tls.Config
+func BuildTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.MinVersion != "" {
+		version, ok := tlsVersions[opts.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("不支持的TLS版本: %s", opts.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if opts.CAFile != "" {
+		caData, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %w", err)
+		}
+		// 在系统CA的基础上追加自定义CA
+		certPool, err := x509.SystemCertPool()
+		if err != nil || certPool == nil {
+			certPool = x509.NewCertPool()
+		}
+		if !certPool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("CA证书 %s 中没有有效的PEM证书", opts.CAFile)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, fmt.Errorf("客户端证书和私钥必须同时配置")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// SetTLSConfig 为客户端配置TLS（自定义CA、客户端证书等）
+func (c *ZabbixClient) SetTLSConfig(opts TLSOptions) error {
+	tlsConfig, err := BuildTLSConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	c.transport().TLSClientConfig = tlsConfig
+	return nil
+}
+
+// SetProxy 设置访问Zabbix API使用的代理，支持 http://、https:// 和 socks5:// 地址
+func (c *ZabbixClient) SetProxy(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("解析代理地址失败: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("不支持的代理协议: %s", u.Scheme)
+	}
+
+	c.transport().Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// SetExtraHeaders 设置每个API请求都附带的额外HTTP头部（例如反向代理要求的 X-Api-Key）。
+// Content-Type 和 Zabbix 7.0+ 的 Authorization 头部总是由客户端设置，不能被覆盖。
+// 头部的值通常是密钥，会登记到日志脱敏中；再次设置时移除之前登记的值。
+func (c *ZabbixClient) SetExtraHeaders(headers map[string]string) {
+	c.headersMu.Lock()
+	defer c.headersMu.Unlock()
+
+	// 先登记新值再移除旧值，相同的值不会出现未屏蔽的间隙
+	for _, v := range headers {
+		redact.Register(v)
+	}
+	for _, v := range c.extraHeaders {
+		redact.Unregister(v)
+	}
+
+	c.extraHeaders = make(map[string]string, len(headers))
+	for k, v := range headers {
+		c.extraHeaders[k] = v
+	}
+}
+
+// transport 获取客户端专用的 http.Transport，首次调用时从默认Transport复制一份，
+// 避免修改全局的 http.DefaultTransport
+func (c *ZabbixClient) transport() *http.Transport {
+	if t, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+		return t
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	c.HTTPClient.Transport = t
+	return t
+}
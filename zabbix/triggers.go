@@ -1,9 +1,17 @@
 package zabbix
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // GetTriggers 获取触发器
 func (c *ZabbixClient) GetTriggers(hostID string, active bool) ([]map[string]interface{}, error) {
+	return c.GetTriggersContext(context.Background(), hostID, active)
+}
+
+// GetTriggersContext 获取触发器（支持context取消）
+func (c *ZabbixClient) GetTriggersContext(ctx context.Context, hostID string, active bool) ([]map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":      "extend",
 		"selectHosts": "extend",
@@ -20,7 +28,7 @@ func (c *ZabbixClient) GetTriggers(hostID string, active bool) ([]map[string]int
 		}
 	}
 
-	result, err := c.Call("trigger.get", params)
+	result, err := c.CallContext(ctx, "trigger.get", params)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +50,11 @@ func (c *ZabbixClient) GetTriggers(hostID string, active bool) ([]map[string]int
 
 // GetTriggerByID 根据触发器ID获取触发器信息
 func (c *ZabbixClient) GetTriggerByID(triggerID string) (map[string]interface{}, error) {
+	return c.GetTriggerByIDContext(context.Background(), triggerID)
+}
+
+// GetTriggerByIDContext 根据触发器ID获取触发器信息（支持context取消）
+func (c *ZabbixClient) GetTriggerByIDContext(ctx context.Context, triggerID string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"output":             "extend",
 		"selectHosts":        "extend",
@@ -50,14 +63,14 @@ func (c *ZabbixClient) GetTriggerByID(triggerID string) (map[string]interface{},
 		"triggerids":         triggerID,
 	}
 
-	result, err := c.Call("trigger.get", params)
+	result, err := c.CallContext(ctx, "trigger.get", params)
 	if err != nil {
 		return nil, err
 	}
 
 	triggers, ok := result.([]interface{})
 	if !ok || len(triggers) == 0 {
-		return nil, fmt.Errorf("触发器不存在")
+		return nil, &NotFoundError{Object: "触发器"}
 	}
 
 	if trigger, ok := triggers[0].(map[string]interface{}); ok {
@@ -69,6 +82,11 @@ func (c *ZabbixClient) GetTriggerByID(triggerID string) (map[string]interface{},
 
 // CreateTrigger 创建触发器
 func (c *ZabbixClient) CreateTrigger(description, expression string, priority int) (string, error) {
+	return c.CreateTriggerContext(context.Background(), description, expression, priority)
+}
+
+// CreateTriggerContext 创建触发器（支持context取消）
+func (c *ZabbixClient) CreateTriggerContext(ctx context.Context, description, expression string, priority int) (string, error) {
 	params := map[string]interface{}{
 		"description": description,
 		"expression":  expression,
@@ -76,7 +94,7 @@ func (c *ZabbixClient) CreateTrigger(description, expression string, priority in
 		"status":      0, // 启用状态
 	}
 
-	result, err := c.Call("trigger.create", params)
+	result, err := c.CallContext(ctx, "trigger.create", params)
 	if err != nil {
 		return "", err
 	}
@@ -96,34 +114,54 @@ func (c *ZabbixClient) CreateTrigger(description, expression string, priority in
 
 // UpdateTrigger 更新触发器
 func (c *ZabbixClient) UpdateTrigger(triggerID string, params map[string]interface{}) error {
+	return c.UpdateTriggerContext(context.Background(), triggerID, params)
+}
+
+// UpdateTriggerContext 更新触发器（支持context取消）
+func (c *ZabbixClient) UpdateTriggerContext(ctx context.Context, triggerID string, params map[string]interface{}) error {
 	params["triggerid"] = triggerID
-	_, err := c.Call("trigger.update", params)
+	_, err := c.CallContext(ctx, "trigger.update", params)
 	return err
 }
 
 // DeleteTrigger 删除触发器
 func (c *ZabbixClient) DeleteTrigger(triggerID string) error {
+	return c.DeleteTriggerContext(context.Background(), triggerID)
+}
+
+// DeleteTriggerContext 删除触发器（支持context取消）
+func (c *ZabbixClient) DeleteTriggerContext(ctx context.Context, triggerID string) error {
 	params := []string{triggerID}
-	_, err := c.Call("trigger.delete", params)
+	_, err := c.CallContext(ctx, "trigger.delete", params)
 	return err
 }
 
 // EnableTrigger 启用触发器
 func (c *ZabbixClient) EnableTrigger(triggerID string) error {
+	return c.EnableTriggerContext(context.Background(), triggerID)
+}
+
+// EnableTriggerContext 启用触发器（支持context取消）
+func (c *ZabbixClient) EnableTriggerContext(ctx context.Context, triggerID string) error {
 	params := map[string]interface{}{
 		"triggerid": triggerID,
 		"status":    0,
 	}
-	_, err := c.Call("trigger.update", params)
+	_, err := c.CallContext(ctx, "trigger.update", params)
 	return err
 }
 
 // DisableTrigger 禁用触发器
 func (c *ZabbixClient) DisableTrigger(triggerID string) error {
+	return c.DisableTriggerContext(context.Background(), triggerID)
+}
+
+// DisableTriggerContext 禁用触发器（支持context取消）
+func (c *ZabbixClient) DisableTriggerContext(ctx context.Context, triggerID string) error {
 	params := map[string]interface{}{
 		"triggerid": triggerID,
 		"status":    1,
 	}
-	_, err := c.Call("trigger.update", params)
+	_, err := c.CallContext(ctx, "trigger.update", params)
 	return err
 }
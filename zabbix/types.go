@@ -1,6 +1,9 @@
 package zabbix
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // JSONRPCRequest JSON-RPC请求结构
 type JSONRPCRequest struct {
@@ -30,3 +33,35 @@ type RPCError struct {
 func (e *RPCError) Error() string {
 	return fmt.Sprintf("Zabbix API Error %d: %s (%s)", e.Code, e.Message, e.Data)
 }
+
+// HTTPStatusError Zabbix前端（或其前面的反向代理）返回了非2xx状态码
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error 实现error接口
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP状态码 %d: %s", e.StatusCode, e.Body)
+}
+
+// NotFoundError 查询的对象不存在
+type NotFoundError struct {
+	Object string // 对象类型，如 "主机"
+}
+
+// Error 实现error接口
+func (e *NotFoundError) Error() string {
+	return e.Object + "不存在"
+}
+
+// ErrPHPTimeout Zabbix前端PHP脚本执行超时
+var ErrPHPTimeout = errors.New("Zabbix前端PHP执行超时")
+
+// truncate 截断过长的字符串，用于在错误信息中附带响应片段
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
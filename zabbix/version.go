@@ -1,6 +1,7 @@
 package zabbix
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -25,11 +26,16 @@ func NewVersionDetector(client *ZabbixClient) *VersionDetector {
 	return &VersionDetector{client: client}
 }
 
-// DetectVersion 检测Zabbix版本
+// DetectVersion 检测Zabbix版本（每次都会请求apiinfo.version，一般应使用 ZabbixClient.GetVersion 的缓存结果）
 func (vd *VersionDetector) DetectVersion() (*VersionInfo, error) {
+	return vd.DetectVersionContext(context.Background())
+}
+
+// DetectVersionContext 检测Zabbix版本（支持context取消）
+func (vd *VersionDetector) DetectVersionContext(ctx context.Context) (*VersionInfo, error) {
 	// 获取API版本信息 - 使用内部调用避免循环依赖
 	// Zabbix API要求params为空数组[]而不是nil
-	result, err := vd.client.callWithAuth("apiinfo.version", []interface{}{}, "")
+	result, err := vd.client.callWithAuth(ctx, "apiinfo.version", []interface{}{}, "")
 	if err != nil {
 		return nil, fmt.Errorf("获取API版本失败: %w", err)
 	}
@@ -45,11 +51,8 @@ func (vd *VersionDetector) DetectVersion() (*VersionInfo, error) {
 		return nil, fmt.Errorf("解析版本号失败: %w", err)
 	}
 
-	// 获取完整的版本信息（如果可用）
-	if versionInfo, err := vd.getFullVersion(); err == nil {
-		version.Full = versionInfo
-	}
-
+	// apiinfo.version 返回的就是完整版本号，无需再次请求
+	version.Full = apiVersion
 	version.APIVersion = apiVersion
 	return version, nil
 }
@@ -91,25 +94,9 @@ func (vd *VersionDetector) parseVersion(versionStr string) (*VersionInfo, error)
 	}, nil
 }
 
-// getFullVersion 获取完整版本信息
-func (vd *VersionDetector) getFullVersion() (string, error) {
-	// apiinfo.version方法不接受任何参数，params必须为空数组
-	// 使用内部调用避免循环依赖
-	result, err := vd.client.callWithAuth("apiinfo.version", []interface{}{}, "")
-	if err != nil {
-		return "", err
-	}
-
-	if version, ok := result.(string); ok {
-		return version, nil
-	}
-
-	return "", fmt.Errorf("无法获取完整版本信息")
-}
-
 // IsVersionSupported 检查版本是否受支持
 func (vd *VersionDetector) IsVersionSupported(minVersion string) (bool, error) {
-	currentVersion, err := vd.DetectVersion()
+	currentVersion, err := vd.client.GetVersion()
 	if err != nil {
 		return false, err
 	}
@@ -135,7 +122,7 @@ func (vd *VersionDetector) compareVersions(v1, v2 *VersionInfo) int {
 
 // GetCompatibleFeatures 获取当前版本支持的功能
 func (vd *VersionDetector) GetCompatibleFeatures() map[string]bool {
-	version, err := vd.DetectVersion()
+	version, err := vd.client.GetVersion()
 	if err != nil {
 		return vd.getDefaultFeatures()
 	}
@@ -190,7 +177,7 @@ func (vd *VersionDetector) getDefaultFeatures() map[string]bool {
 
 // AdaptAPIParams 根据版本适配API参数
 func (vd *VersionDetector) AdaptAPIParams(method string, params map[string]interface{}) map[string]interface{} {
-	version, err := vd.DetectVersion()
+	version, err := vd.client.GetVersion()
 	if err != nil {
 		return params
 	}
@@ -228,7 +215,7 @@ func (vd *VersionDetector) AdaptAPIParams(method string, params map[string]inter
 
 // GetVersionSpecificEndpoint 获取版本特定的端点
 func (vd *VersionDetector) GetVersionSpecificEndpoint(endpoint string) string {
-	version, err := vd.DetectVersion()
+	version, err := vd.client.GetVersion()
 	if err != nil {
 		return endpoint
 	}
@@ -257,7 +244,7 @@ func (v *VersionInfo) String() string {
 
 // 在 version.go 中添加更详细的版本特性映射
 func (vd *VersionDetector) GetDetailedVersionFeatures() map[string]interface{} {
-	version, err := vd.DetectVersion()
+	version, err := vd.client.GetVersion()
 	if err != nil {
 		// 将 map[string]bool 转换为 map[string]interface{}
 		defaultFeatures := vd.getDefaultFeatures()
@@ -295,7 +282,7 @@ func (vd *VersionDetector) TestVersionCompatibility() map[string]interface{} {
 	result := make(map[string]interface{})
 
 	// 测试版本检测
-	version, err := vd.DetectVersion()
+	version, err := vd.client.GetVersion()
 	if err != nil {
 		result["version_detection"] = map[string]string{
 			"status": "failed",
@@ -1,15 +1,19 @@
 package zabbix
 
 import (
+	"context"
 	"fmt"
 )
 
 // CallWithVersion 版本感知的API调用
 func (c *ZabbixClient) CallWithVersion(method string, params interface{}) (interface{}, error) {
-	detector := NewVersionDetector(c)
+	return c.CallWithVersionContext(context.Background(), method, params)
+}
 
+// CallWithVersionContext 版本感知的API调用（支持context取消）
+func (c *ZabbixClient) CallWithVersionContext(ctx context.Context, method string, params interface{}) (interface{}, error) {
 	// 获取版本信息
-	version, err := detector.DetectVersion()
+	version, err := c.GetVersionContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -35,21 +39,25 @@ func (c *ZabbixClient) CallWithVersion(method string, params interface{}) (inter
 		}
 	}
 
-	return c.Call(adaptedMethod, adaptedParams)
+	return c.CallContext(ctx, adaptedMethod, adaptedParams)
 }
 
 // CallWithFallback 带回退机制的API调用
 func (c *ZabbixClient) CallWithFallback(primaryMethod, fallbackMethod string, params interface{}) (interface{}, error) {
+	return c.CallWithFallbackContext(context.Background(), primaryMethod, fallbackMethod, params)
+}
+
+// CallWithFallbackContext 带回退机制的API调用（支持context取消）
+func (c *ZabbixClient) CallWithFallbackContext(ctx context.Context, primaryMethod, fallbackMethod string, params interface{}) (interface{}, error) {
 	// 首先尝试主要方法
-	result, err := c.Call(primaryMethod, params)
+	result, err := c.CallContext(ctx, primaryMethod, params)
 	if err != nil {
 		// 检查是否是方法不存在的错误
 		if rpcErr, ok := err.(*RPCError); ok && rpcErr.Code == -32601 {
 			// 尝试回退方法
 			if fallbackMethod != "" {
-				// 使用日志记录
-				fmt.Printf("主要方法 %s 失败，尝试回退方法 %s\n", primaryMethod, fallbackMethod)
-				return c.Call(fallbackMethod, params)
+				getLogger().Infof("主要方法 %s 不存在，尝试回退方法 %s", primaryMethod, fallbackMethod)
+				return c.CallContext(ctx, fallbackMethod, params)
 			}
 		}
 		return nil, err
@@ -81,10 +89,15 @@ func convertProblemToTriggerParams(problemParams map[string]interface{}) map[str
 
 // VersionCompatibleCall 根据版本兼容性调用API
 func (c *ZabbixClient) VersionCompatibleCall(method string, params interface{}, minVersion string) (interface{}, error) {
+	return c.VersionCompatibleCallContext(context.Background(), method, params, minVersion)
+}
+
+// VersionCompatibleCallContext 根据版本兼容性调用API（支持context取消）
+func (c *ZabbixClient) VersionCompatibleCallContext(ctx context.Context, method string, params interface{}, minVersion string) (interface{}, error) {
 	detector := NewVersionDetector(c)
 
 	// 获取版本信息
-	version, err := detector.DetectVersion()
+	version, err := c.GetVersionContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +113,7 @@ func (c *ZabbixClient) VersionCompatibleCall(method string, params interface{},
 		return nil, fmt.Errorf("当前Zabbix版本 %s 不满足最低版本要求 %s", version.String(), minVersion)
 	}
 
-	return c.Call(method, params)
+	return c.CallContext(ctx, method, params)
 }
 
 // isVersionCompatible 检查版本兼容性